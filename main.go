@@ -12,19 +12,21 @@ import (
 
 const VERSION = "0.3.0"
 const help = `
-Usage: clickhouse-sql-parser [YOUR SQL STRING] -f [YOUR SQL FILE] -format
+Usage: clickhouse-sql-parser [YOUR SQL STRING] -f [YOUR SQL FILE] -format -schema
 `
 
 var options struct {
 	help    bool
 	file    string
 	format  bool
+	schema  bool
 	version bool
 }
 
 func init() {
 	flag.BoolVar(&options.format, "format", false, "Beautify print the ClickHouse SQL")
 	flag.StringVar(&options.file, "f", "", "Parse SQL from file")
+	flag.BoolVar(&options.schema, "schema", false, "Print the JSON Schema of the AST output")
 	flag.BoolVar(&options.help, "h", false, "Print help message")
 	flag.BoolVar(&options.version, "v", false, "Print version")
 }
@@ -35,6 +37,10 @@ func main() {
 		fmt.Println("v" + VERSION)
 		os.Exit(0)
 	}
+	if options.schema {
+		fmt.Println(string(clickhouse.JSONSchema()))
+		os.Exit(0)
+	}
 	if len(os.Args) < 2 || options.help {
 		fmt.Print(help)
 		os.Exit(0)
@@ -60,7 +66,14 @@ func main() {
 		panic(fmt.Sprintf("parse statements error: %s", err.Error()))
 	}
 	if !options.format { // print AST
-		bytes, _ := json.MarshalIndent(stmts, "", "  ") // nolint
+		data, err := clickhouse.MarshalStatements(stmts)
+		if err != nil {
+			panic(fmt.Sprintf("marshal statements error: %s", err.Error()))
+		}
+		bytes, _ := json.MarshalIndent(struct { // nolint
+			SchemaVersion string          `json:"schemaVersion"`
+			Statements    json.RawMessage `json:"statements"`
+		}{clickhouse.SchemaVersion, data}, "", "  ")
 		fmt.Println(string(bytes))
 	} else { // format SQL
 		for _, stmt := range stmts {
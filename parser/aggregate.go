@@ -0,0 +1,65 @@
+package parser
+
+import "strings"
+
+// aggregateFunctions are the names of the ClickHouse aggregate functions
+// that combinators can be applied to.
+var aggregateFunctions = NewSet(
+	"count", "min", "max", "sum", "avg", "any", "anyLast", "anyHeavy", "argMin", "argMax",
+	"avgWeighted", "corr", "covarPop", "covarSamp", "entropy", "first_value", "last_value",
+	"groupArray", "groupArrayInsertAt", "groupArrayMovingAvg", "groupArrayMovingSum",
+	"groupArraySample", "groupArrayLast", "groupUniqArray", "groupConcat",
+	"groupBitAnd", "groupBitOr", "groupBitXor", "groupBitmap", "groupBitmapAnd", "groupBitmapOr",
+	"groupBitmapXor", "histogram", "kurtPop", "kurtSamp", "skewPop", "skewSamp",
+	"minMap", "maxMap", "sumMap", "sumMapWithOverflow", "sumCount", "sumKahan", "sumWithOverflow",
+	"median", "medianExact", "medianExactWeighted", "medianTiming", "medianTimingWeighted",
+	"medianDeterministic", "medianTDigest", "medianTDigestWeighted", "medianBFloat16",
+	"quantile", "quantiles", "quantileExact", "quantilesExact", "quantileExactLow",
+	"quantileExactHigh", "quantileExactWeighted", "quantilesExactWeighted", "quantileTiming",
+	"quantilesTiming", "quantileTimingWeighted", "quantilesTimingWeighted", "quantileDeterministic",
+	"quantilesDeterministic", "quantileTDigest", "quantilesTDigest", "quantileTDigestWeighted",
+	"quantilesTDigestWeighted", "quantileBFloat16", "quantilesBFloat16", "quantileGK", "quantilesGK",
+	"retention", "sequenceMatch", "sequenceCount", "sequenceNextNode", "windowFunnel",
+	"simpleLinearRegression", "stochasticLinearRegression", "stochasticLogisticRegression",
+	"stddevPop", "stddevSamp", "varPop", "varSamp", "studentTTest", "welchTTest", "meanZTest",
+	"mannWhitneyUTest", "kolmogorovSmirnovTest", "analysisOfVariance", "rankCorr",
+	"topK", "topKWeighted", "uniq", "uniqExact", "uniqCombined", "uniqCombined64", "uniqHLL12",
+	"uniqTheta", "uniqUpTo", "boundingRatio", "categoricalInformationValue", "contingency",
+	"cramersV", "cramersVBiasCorrected", "theilsU", "maxIntersections", "maxIntersectionsPosition",
+	"deltaSum", "deltaSumTimestamp", "exponentialMovingAverage", "intervalLengthSum", "sparkbar",
+	"largestTriangleThreeBuckets", "singleValueOrNull",
+)
+
+// aggregateCombinators are the suffixes that modify an aggregate function,
+// longest first so that e.g. SimpleState is not taken for State.
+var aggregateCombinators = []string{
+	"SimpleState", "OrDefault", "Resample", "Distinct", "ForEach", "OrNull", "ArgMin", "ArgMax",
+	"Array", "State", "Merge", "Map", "If",
+}
+
+// SplitAggregateCombinators splits the name of an aggregate function call
+// into the base aggregate function and the combinators applied to it, in
+// the order they are written. For example, uniqMergeState gives uniq with
+// the Merge and State combinators, and sumIf gives sum with If. ok is false
+// if name is not a known aggregate function with zero or more combinators.
+func SplitAggregateCombinators(name string) (base string, combinators []string, ok bool) {
+	if isAggregateFunction(name) {
+		return name, nil, true
+	}
+	for _, combinator := range aggregateCombinators {
+		if len(name) <= len(combinator) || !strings.HasSuffix(name, combinator) {
+			continue
+		}
+		base, combinators, ok = SplitAggregateCombinators(strings.TrimSuffix(name, combinator))
+		if ok {
+			return base, append(combinators, combinator), true
+		}
+	}
+	return name, nil, false
+}
+
+// isAggregateFunction reports whether name is an aggregate function. The
+// lower-case names, like count or sum, may be written in any case.
+func isAggregateFunction(name string) bool {
+	return aggregateFunctions.Contains(name) || aggregateFunctions.Contains(strings.ToLower(name))
+}
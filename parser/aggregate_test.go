@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitAggregateCombinators(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		base        string
+		combinators []string
+		ok          bool
+	}{
+		{name: "sum", base: "sum", ok: true},
+		{name: "COUNT", base: "COUNT", ok: true},
+		{name: "sumIf", base: "sum", combinators: []string{"If"}, ok: true},
+		{name: "uniqMergeState", base: "uniq", combinators: []string{"Merge", "State"}, ok: true},
+		{name: "avgOrNull", base: "avg", combinators: []string{"OrNull"}, ok: true},
+		{name: "sumForEach", base: "sum", combinators: []string{"ForEach"}, ok: true},
+		{name: "countResample", base: "count", combinators: []string{"Resample"}, ok: true},
+		{name: "sumSimpleState", base: "sum", combinators: []string{"SimpleState"}, ok: true},
+		{name: "uniqArrayIf", base: "uniq", combinators: []string{"Array", "If"}, ok: true},
+		{name: "sumArgMax", base: "sum", combinators: []string{"ArgMax"}, ok: true},
+		{name: "groupArray", base: "groupArray", ok: true},
+		{name: "argMaxIf", base: "argMax", combinators: []string{"If"}, ok: true},
+		{name: "quantilesTDigestMerge", base: "quantilesTDigest", combinators: []string{"Merge"}, ok: true},
+		{name: "toStartOfDay", base: "toStartOfDay", ok: false},
+		{name: "notAnAggregateIf", base: "notAnAggregateIf", ok: false},
+		{name: "If", base: "If", ok: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			base, combinators, ok := SplitAggregateCombinators(tc.name)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.base, base)
+			require.Equal(t, tc.combinators, combinators)
+		})
+	}
+}
+
+func TestFunctionExpr_Parameters(t *testing.T) {
+	sql := "SELECT quantile(0.95)(latency), sequenceMatch('(?1)(?2)')(ts, a, b), count(x) FROM t"
+	query := parseSingleStatement(t, sql).(*SelectQuery)
+
+	quantile := query.SelectColumns.Items[0].(*FunctionExpr)
+	require.Equal(t, "(0.95)", quantile.Parameters.String(0))
+	require.Equal(t, "(latency)", quantile.Args.String(0))
+	require.Equal(t, "quantile(0.95)(latency)", sql[quantile.Pos():quantile.End()+1])
+
+	sequenceMatch := query.SelectColumns.Items[1].(*FunctionExpr)
+	require.Len(t, sequenceMatch.Parameters.Items.Items, 1)
+	require.Len(t, sequenceMatch.Args.Items.Items, 3)
+	require.Equal(t, "sequenceMatch('(?1)(?2)')(ts, a, b)", sequenceMatch.String(0))
+
+	count := query.SelectColumns.Items[2].(*FunctionExpr)
+	require.Nil(t, count.Parameters)
+	require.Equal(t, "(x)", count.Args.String(0))
+}
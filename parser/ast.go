@@ -44,6 +44,7 @@ type TernaryExpr struct {
 	Condition Expr
 	TrueExpr  Expr
 	FalseExpr Expr
+	Trivia    *Trivia
 }
 
 func (t *TernaryExpr) Pos() Pos {
@@ -61,7 +62,7 @@ func (t *TernaryExpr) String(level int) string {
 	builder.WriteString(t.TrueExpr.String(level))
 	builder.WriteString(" : ")
 	builder.WriteString(t.FalseExpr.String(level))
-	return builder.String()
+	return t.Trivia.format(builder.String(), level, "", false)
 }
 
 type BinaryExpr struct {
@@ -70,6 +71,7 @@ type BinaryExpr struct {
 	RightExpr Expr
 	HasGlobal bool
 	HasNot    bool
+	Trivia    *Trivia
 }
 
 func (p *BinaryExpr) Pos() Pos {
@@ -82,8 +84,8 @@ func (p *BinaryExpr) End() Pos {
 
 func (p *BinaryExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString(p.LeftExpr.String(level))
-	builder.WriteByte(' ')
+	left, right := beforeOperator(p.LeftExpr.String(level), p.RightExpr, level)
+	builder.WriteString(left)
 	if p.HasNot {
 		builder.WriteString("NOT ")
 	} else if p.HasGlobal {
@@ -91,8 +93,78 @@ func (p *BinaryExpr) String(level int) string {
 	}
 	builder.WriteString(string(p.Operation))
 	builder.WriteByte(' ')
-	builder.WriteString(p.RightExpr.String(level))
-	return builder.String()
+	builder.WriteString(right.String(level))
+	return p.Trivia.format(builder.String(), level, "", false)
+}
+
+// BetweenExpr is a range predicate like x BETWEEN a AND b, or
+// x NOT BETWEEN a AND b when Not is set. The bounds are inclusive.
+type BetweenExpr struct {
+	Expr       Expr
+	Not        bool
+	BetweenPos Pos
+	Low        Expr
+	AndPos     Pos
+	High       Expr
+	Trivia     *Trivia
+}
+
+func (b *BetweenExpr) Pos() Pos {
+	return b.Expr.Pos()
+}
+
+func (b *BetweenExpr) End() Pos {
+	return b.High.End()
+}
+
+func (b *BetweenExpr) String(level int) string {
+	var builder strings.Builder
+	builder.WriteString(b.Expr.String(level))
+	if b.Not {
+		builder.WriteString(" NOT")
+	}
+	builder.WriteString(" BETWEEN ")
+	builder.WriteString(b.Low.String(level))
+	builder.WriteString(" AND ")
+	builder.WriteString(b.High.String(level))
+	return b.Trivia.format(builder.String(), level, "", false)
+}
+
+// LambdaExpr is a lambda function like x -> x * 2 or (k, v) -> v > 0,
+// which is passed to higher-order functions such as arrayMap.
+type LambdaExpr struct {
+	LambdaPos Pos
+	Params    []*Ident
+	ArrowPos  Pos
+	Body      Expr
+	Trivia    *Trivia
+}
+
+func (l *LambdaExpr) Pos() Pos {
+	return l.LambdaPos
+}
+
+func (l *LambdaExpr) End() Pos {
+	return l.Body.End()
+}
+
+func (l *LambdaExpr) String(level int) string {
+	var builder strings.Builder
+	if len(l.Params) == 1 {
+		builder.WriteString(l.Params[0].String(level))
+	} else {
+		builder.WriteByte('(')
+		for i, param := range l.Params {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(param.String(level))
+		}
+		builder.WriteByte(')')
+	}
+	builder.WriteString(" -> ")
+	builder.WriteString(l.Body.String(level))
+	return l.Trivia.format(builder.String(), level, "", false)
 }
 
 type AlterTableExpr interface {
@@ -106,6 +178,7 @@ type AlterTable struct {
 	TableIdentifier *TableIdentifier
 	OnCluster       *OnClusterExpr
 	AlterExprs      []AlterTableExpr
+	Trivia          *Trivia
 }
 
 func (a *AlterTable) Pos() Pos {
@@ -135,7 +208,7 @@ func (a *AlterTable) String(level int) string {
 			builder.WriteString(",")
 		}
 	}
-	return builder.String()
+	return a.Trivia.format(builder.String(), level, "", false)
 }
 
 type AlterTableAttachPartition struct {
@@ -644,11 +717,26 @@ func (a *TableIndex) String(level int) string {
 	return builder.String()
 }
 
+// IdentQuote is the character quoting an identifier.
+type IdentQuote string
+
+const (
+	IdentQuoteBacktick    IdentQuote = "`"
+	IdentQuoteDoubleQuote IdentQuote = "\""
+)
+
 type Ident struct {
 	Name     string
-	Unquoted bool
-	NamePos  Pos
-	NameEnd  Pos
+	Unquoted bool // true if the identifier is quoted
+	// Quote is the quote style of a quoted identifier. Quoted identifiers
+	// without a quote style are formatted with backticks.
+	Quote   IdentQuote
+	NamePos Pos
+	NameEnd Pos
+	// Param is set instead of Name if the identifier is given by a query
+	// parameter, e.g. {db:Identifier}.
+	Param  *QueryParam
+	Trivia *Trivia
 }
 
 func (i *Ident) Pos() Pos {
@@ -659,11 +747,40 @@ func (i *Ident) End() Pos {
 	return i.NameEnd
 }
 
-func (i *Ident) String(int) string {
-	if i.Unquoted {
-		return "`" + i.Name + "`"
+func (i *Ident) String(level int) string {
+	name := i.Name
+	if i.Param != nil {
+		name = i.Param.String(level)
+	} else if i.Unquoted {
+		if i.Quote == IdentQuoteDoubleQuote {
+			name = quoteString(i.Name, '"')
+		} else {
+			name = quoteString(i.Name, '`')
+		}
 	}
-	return i.Name
+	return i.Trivia.format(name, level, "", false)
+}
+
+// QueryParam is a query parameter placeholder like {id:UInt64}, whose
+// value is passed separately when the query is executed.
+type QueryParam struct {
+	LeftBracePos  Pos
+	RightBracePos Pos
+	Name          *Ident
+	Type          Expr
+	Trivia        *Trivia
+}
+
+func (q *QueryParam) Pos() Pos {
+	return q.LeftBracePos
+}
+
+func (q *QueryParam) End() Pos {
+	return q.RightBracePos + 1
+}
+
+func (q *QueryParam) String(level int) string {
+	return q.Trivia.format("{"+q.Name.String(level)+":"+q.Type.String(level)+"}", level, "", false)
 }
 
 type UUID struct {
@@ -689,6 +806,7 @@ type CreateDatabase struct {
 	IfNotExists  bool // true if 'IF NOT EXISTS' is specified
 	OnCluster    *OnClusterExpr
 	Engine       *EngineExpr
+	Trivia       *Trivia
 }
 
 func (c *CreateDatabase) Pos() Pos {
@@ -718,7 +836,7 @@ func (c *CreateDatabase) String(level int) string {
 		builder.WriteString(NewLine(level))
 		builder.WriteString(c.Engine.String(level))
 	}
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type CreateTable struct {
@@ -732,6 +850,7 @@ type CreateTable struct {
 	Engine       *EngineExpr
 	SubQuery     *SubQueryExpr
 	HasTemporary bool
+	Trivia       *Trivia
 }
 
 func (c *CreateTable) Pos() Pos {
@@ -775,7 +894,7 @@ func (c *CreateTable) String(level int) string {
 	if c.SubQuery != nil {
 		builder.WriteString(c.SubQuery.String(level))
 	}
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type CreateMaterializedView struct {
@@ -790,6 +909,7 @@ type CreateMaterializedView struct {
 	Destination  *DestinationExpr
 	SubQuery     *SubQueryExpr
 	Populate     bool
+	Trivia       *Trivia
 }
 
 func (c *CreateMaterializedView) Pos() Pos {
@@ -833,7 +953,7 @@ func (c *CreateMaterializedView) String(level int) string {
 	if c.SubQuery != nil {
 		builder.WriteString(c.SubQuery.String(level))
 	}
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type CreateView struct {
@@ -845,6 +965,7 @@ type CreateView struct {
 	OnCluster    *OnClusterExpr
 	TableSchema  *TableSchemaExpr
 	SubQuery     *SubQueryExpr
+	Trivia       *Trivia
 }
 
 func (c *CreateView) Pos() Pos {
@@ -884,7 +1005,7 @@ func (c *CreateView) String(level int) string {
 	if c.SubQuery != nil {
 		builder.WriteString(c.SubQuery.String(level))
 	}
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type CreateFunction struct {
@@ -894,6 +1015,7 @@ type CreateFunction struct {
 	OnCluster    *OnClusterExpr
 	Params       *ParamExprList
 	Expr         Expr
+	Trivia       *Trivia
 }
 
 func (c *CreateFunction) Type() string {
@@ -923,7 +1045,7 @@ func (c *CreateFunction) String(level int) string {
 	builder.WriteString(c.Params.String(level))
 	builder.WriteString(" -> ")
 	builder.WriteString(c.Expr.String(level))
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type RoleName struct {
@@ -970,7 +1092,10 @@ func (s *SettingPair) Pos() Pos {
 }
 
 func (s *SettingPair) End() Pos {
-	return s.Value.End()
+	if s.Value != nil {
+		return s.Value.End()
+	}
+	return s.Name.NameEnd
 }
 
 func (s *SettingPair) String(level int) string {
@@ -1025,6 +1150,7 @@ type CreateRole struct {
 	RoleNames         []*RoleName
 	AccessStorageType *Ident
 	Settings          []*RoleSetting
+	Trivia            *Trivia
 }
 
 func (c *CreateRole) Pos() Pos {
@@ -1068,7 +1194,7 @@ func (c *CreateRole) String(level int) string {
 			builder.WriteString(setting.String(level))
 		}
 	}
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type AlterRole struct {
@@ -1077,6 +1203,7 @@ type AlterRole struct {
 	IfExists        bool
 	RoleRenamePairs []*RoleRenamePair
 	Settings        []*RoleSetting
+	Trivia          *Trivia
 }
 
 func (a *AlterRole) Pos() Pos {
@@ -1112,7 +1239,7 @@ func (a *AlterRole) String(level int) string {
 			builder.WriteString(setting.String(level))
 		}
 	}
-	return builder.String()
+	return a.Trivia.format(builder.String(), level, "", false)
 }
 
 type RoleRenamePair struct {
@@ -1183,6 +1310,7 @@ func (c *ConstraintExpr) String(level int) string {
 
 type NullLiteral struct {
 	NullPos Pos
+	Trivia  *Trivia
 }
 
 func (n *NullLiteral) Pos() Pos {
@@ -1193,8 +1321,8 @@ func (n *NullLiteral) End() Pos {
 	return n.NullPos + 4
 }
 
-func (n *NullLiteral) String(int) string {
-	return "NULL"
+func (n *NullLiteral) String(level int) string {
+	return n.Trivia.format("NULL", level, "", false)
 }
 
 type NotNullLiteral struct {
@@ -1217,6 +1345,7 @@ func (n *NotNullLiteral) String(int) string {
 type NestedIdentifier struct {
 	Ident    *Ident
 	DotIdent *Ident
+	Trivia   *Trivia
 }
 
 func (n *NestedIdentifier) Pos() Pos {
@@ -1230,17 +1359,19 @@ func (n *NestedIdentifier) End() Pos {
 	return n.Ident.NameEnd
 }
 
-func (n *NestedIdentifier) String(int) string {
+func (n *NestedIdentifier) String(level int) string {
+	name := n.Ident.String(0)
 	if n.DotIdent != nil {
-		return n.Ident.String(0) + "." + n.DotIdent.String(0)
+		name += "." + n.DotIdent.String(0)
 	}
-	return n.Ident.String(0)
+	return n.Trivia.format(name, level, "", false)
 }
 
 type ColumnIdentifier struct {
 	Database *Ident
 	Table    *Ident
 	Column   *Ident
+	Trivia   *Trivia
 }
 
 func (c *ColumnIdentifier) Pos() Pos {
@@ -1257,19 +1388,20 @@ func (c *ColumnIdentifier) End() Pos {
 	return c.Column.NameEnd
 }
 
-func (c *ColumnIdentifier) String(int) string {
+func (c *ColumnIdentifier) String(level int) string {
+	name := c.Column.String(0)
 	if c.Database != nil {
-		return c.Database.String(0) + "." + c.Table.String(0) + "." + c.Column.String(0)
+		name = c.Database.String(0) + "." + c.Table.String(0) + "." + name
 	} else if c.Table != nil {
-		return c.Table.String(0) + "." + c.Column.String(0)
-	} else {
-		return c.Column.String(0)
+		name = c.Table.String(0) + "." + name
 	}
+	return c.Trivia.format(name, level, "", false)
 }
 
 type TableIdentifier struct {
 	Database *Ident
 	Table    *Ident
+	Trivia   *Trivia
 }
 
 func (t *TableIdentifier) Pos() Pos {
@@ -1283,11 +1415,12 @@ func (t *TableIdentifier) End() Pos {
 	return t.Table.NameEnd
 }
 
-func (t *TableIdentifier) String(int) string {
+func (t *TableIdentifier) String(level int) string {
+	name := t.Table.String(0)
 	if t.Database != nil {
-		return t.Database.String(0) + "." + t.Table.String(0)
+		name = t.Database.String(0) + "." + name
 	}
-	return t.Table.String(0)
+	return t.Trivia.format(name, level, "", false)
 }
 
 type TableSchemaExpr struct {
@@ -1311,11 +1444,18 @@ func (t *TableSchemaExpr) String(level int) string {
 	if len(t.Columns) > 0 {
 		builder.WriteString("(")
 		for i, column := range t.Columns {
-			if i > 0 {
-				builder.WriteByte(',')
-			}
 			builder.WriteString(NewLine(level + 1))
-			builder.WriteString(column.String(level))
+			separator := ","
+			if i == len(t.Columns)-1 {
+				separator = ""
+			}
+			if c, ok := column.(*Column); ok {
+				// the separator must precede a trailing line comment
+				builder.WriteString(c.Trivia.format(c.definition(level), level+1, separator, true))
+			} else {
+				builder.WriteString(column.String(level))
+				builder.WriteString(separator)
+			}
 		}
 		builder.WriteString(NewLine(level - 1))
 		builder.WriteByte(')')
@@ -1359,8 +1499,9 @@ func (t *TableArgListExpr) String(level int) string {
 }
 
 type TableFunctionExpr struct {
-	Name *Ident
-	Args *TableArgListExpr
+	Name   *Ident
+	Args   *TableArgListExpr
+	Trivia *Trivia
 }
 
 func (t *TableFunctionExpr) Pos() Pos {
@@ -1375,7 +1516,7 @@ func (t *TableFunctionExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString(t.Name.String(level))
 	builder.WriteString(t.Args.String(level))
-	return builder.String()
+	return t.Trivia.format(builder.String(), level, "", false)
 }
 
 type OnClusterExpr struct {
@@ -1452,6 +1593,7 @@ func (p *PartitionExpr) String(level int) string {
 type PartitionByExpr struct {
 	PartitionPos Pos
 	Expr         Expr
+	Trivia       *Trivia
 }
 
 func (p *PartitionByExpr) Pos() Pos {
@@ -1466,12 +1608,13 @@ func (p *PartitionByExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString("PARTITION BY ")
 	builder.WriteString(p.Expr.String(level))
-	return builder.String()
+	return p.Trivia.format(builder.String(), level, "", false)
 }
 
 type PrimaryKeyExpr struct {
 	PrimaryPos Pos
 	Expr       Expr
+	Trivia     *Trivia
 }
 
 func (p *PrimaryKeyExpr) Pos() Pos {
@@ -1486,12 +1629,13 @@ func (p *PrimaryKeyExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString("PRIMARY KEY ")
 	builder.WriteString(p.Expr.String(level))
-	return builder.String()
+	return p.Trivia.format(builder.String(), level, "", false)
 }
 
 type SampleByExpr struct {
 	SamplePos Pos
 	Expr      Expr
+	Trivia    *Trivia
 }
 
 func (s *SampleByExpr) Pos() Pos {
@@ -1506,7 +1650,7 @@ func (s *SampleByExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString("SAMPLE BY ")
 	builder.WriteString(s.Expr.String(level))
-	return builder.String()
+	return s.Trivia.format(builder.String(), level, "", false)
 }
 
 type TTLExpr struct {
@@ -1532,6 +1676,7 @@ type TTLExprList struct {
 	TTLPos  Pos
 	ListEnd Pos
 	Items   []*TTLExpr
+	Trivia  *Trivia
 }
 
 func (t *TTLExprList) Pos() Pos {
@@ -1551,13 +1696,14 @@ func (t *TTLExprList) String(level int) string {
 		}
 		builder.WriteString(item.String(level))
 	}
-	return builder.String()
+	return t.Trivia.format(builder.String(), level, "", false)
 }
 
 type OrderByExpr struct {
 	OrderPos  Pos
 	Expr      Expr
 	Direction OrderDirection
+	Trivia    *Trivia
 }
 
 func (o *OrderByExpr) Pos() Pos {
@@ -1575,13 +1721,14 @@ func (o *OrderByExpr) String(level int) string {
 		builder.WriteByte(' ')
 		builder.WriteString(string(o.Direction))
 	}
-	return builder.String()
+	return o.Trivia.format(builder.String(), level, "", false)
 }
 
 type OrderByListExpr struct {
 	OrderPos Pos
 	ListEnd  Pos
 	Items    []Expr
+	Trivia   *Trivia
 }
 
 func (o *OrderByListExpr) Pos() Pos {
@@ -1602,7 +1749,7 @@ func (o *OrderByListExpr) String(level int) string {
 			builder.WriteByte(' ')
 		}
 	}
-	return builder.String()
+	return o.Trivia.format(builder.String(), level, "", false)
 }
 
 type SettingsExpr struct {
@@ -1631,6 +1778,7 @@ type SettingsExprList struct {
 	SettingsPos Pos
 	ListEnd     Pos
 	Items       []*SettingsExpr
+	Trivia      *Trivia
 }
 
 func (s *SettingsExprList) Pos() Pos {
@@ -1650,14 +1798,14 @@ func (s *SettingsExprList) String(level int) string {
 		}
 		builder.WriteString(item.String(level))
 	}
-	return builder.String()
+	return s.Trivia.format(builder.String(), level, "", false)
 }
 
 type ParamExprList struct {
 	LeftParenPos  Pos
 	RightParenPos Pos
 	Items         *ColumnExprList
-	ColumnArgList *ColumnArgList
+	Trivia        *Trivia
 }
 
 func (f *ParamExprList) Pos() Pos {
@@ -1669,22 +1817,14 @@ func (f *ParamExprList) End() Pos {
 }
 
 func (f *ParamExprList) String(level int) string {
-	var builder strings.Builder
-	builder.WriteString("(")
-	for i, item := range f.Items.Items {
-		if i > 0 {
-			builder.WriteString(", ")
-		}
-		builder.WriteString(item.String(level))
-	}
-	builder.WriteString(")")
-	return builder.String()
+	return f.Trivia.format("("+f.Items.String(level)+")", level, "", false)
 }
 
 type ArrayParamList struct {
 	LeftBracketPos  Pos
 	RightBracketPos Pos
 	Items           *ColumnExprList
+	Trivia          *Trivia
 }
 
 func (a *ArrayParamList) Pos() Pos {
@@ -1696,41 +1836,97 @@ func (a *ArrayParamList) End() Pos {
 }
 
 func (a *ArrayParamList) String(level int) string {
+	return a.Trivia.format("["+a.Items.String(level)+"]", level, "", false)
+}
+
+// IndexExpr is a subscript like arr[1] or m['key']. Subscripts can be
+// chained, as in arr[1][2], and applied to any expression.
+type IndexExpr struct {
+	Object          Expr
+	LeftBracketPos  Pos
+	Index           Expr
+	RightBracketPos Pos
+	Trivia          *Trivia
+}
+
+func (i *IndexExpr) Pos() Pos {
+	return i.Object.Pos()
+}
+
+func (i *IndexExpr) End() Pos {
+	return i.RightBracketPos + 1
+}
+
+func (i *IndexExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString("[")
-	for i, item := range a.Items.Items {
-		if i > 0 {
-			builder.WriteString(", ")
-		}
-		builder.WriteString(item.String(level))
-	}
-	builder.WriteString("]")
-	return builder.String()
+	builder.WriteString(i.Object.String(level))
+	builder.WriteByte('[')
+	builder.WriteString(i.Index.String(level))
+	builder.WriteByte(']')
+	return i.Trivia.format(builder.String(), level, "", false)
 }
 
-type ObjectParams struct {
-	Object Expr
-	Params *ArrayParamList
+// TupleAccessExpr is an access to an element of a tuple by its index, as
+// in tup.1, or by its name, as in tupleElement(x, 'name').name. A name
+// right after a plain identifier, like tup.name, is parsed as a
+// ColumnIdentifier, since it can't be told apart from a table column.
+type TupleAccessExpr struct {
+	Tuple  Expr
+	DotPos Pos
+	Index  Expr // *NumberLiteral or *Ident
+	Trivia *Trivia
 }
 
-func (o *ObjectParams) Pos() Pos {
-	return o.Object.Pos()
+func (t *TupleAccessExpr) Pos() Pos {
+	return t.Tuple.Pos()
 }
 
-func (o *ObjectParams) End() Pos {
-	return o.Params.End()
+func (t *TupleAccessExpr) End() Pos {
+	return t.Index.End()
 }
 
-func (o *ObjectParams) String(level int) string {
+func (t *TupleAccessExpr) String(level int) string {
+	return t.Trivia.format(t.Tuple.String(level)+"."+t.Index.String(level), level, "", false)
+}
+
+// JSONPathExpr is a typed subcolumn of a JSON column like json.a.b.:Int64,
+// which reads the path a.b of json as the type Int64.
+type JSONPathExpr struct {
+	Object  Expr
+	Path    []*Ident
+	TypePos Pos
+	Type    Expr
+	Trivia  *Trivia
+}
+
+func (j *JSONPathExpr) Pos() Pos {
+	return j.Object.Pos()
+}
+
+func (j *JSONPathExpr) End() Pos {
+	return j.Type.End()
+}
+
+func (j *JSONPathExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString(o.Object.String(level))
-	builder.WriteString(o.Params.String(level))
-	return builder.String()
+	builder.WriteString(j.Object.String(level))
+	for _, ident := range j.Path {
+		builder.WriteByte('.')
+		builder.WriteString(ident.String(level))
+	}
+	builder.WriteString(".:")
+	builder.WriteString(j.Type.String(level))
+	return j.Trivia.format(builder.String(), level, "", false)
 }
 
+// FunctionExpr is a function call like f(a, b). A parametric aggregate
+// function like quantile(0.95)(x) also has Parameters, the list before
+// the Args.
 type FunctionExpr struct {
-	Name   *Ident
-	Params *ParamExprList
+	Name       *Ident
+	Parameters *ParamExprList
+	Args       *ParamExprList
+	Trivia     *Trivia
 }
 
 func (f *FunctionExpr) Pos() Pos {
@@ -1738,20 +1934,24 @@ func (f *FunctionExpr) Pos() Pos {
 }
 
 func (f *FunctionExpr) End() Pos {
-	return f.Params.RightParenPos
+	return f.Args.RightParenPos
 }
 
 func (f *FunctionExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString(f.Name.String(level))
-	builder.WriteString(f.Params.String(level))
-	return builder.String()
+	if f.Parameters != nil {
+		builder.WriteString(f.Parameters.String(level))
+	}
+	builder.WriteString(f.Args.String(level))
+	return f.Trivia.format(builder.String(), level, "", false)
 }
 
 type WindowFunctionExpr struct {
 	Function *FunctionExpr
 	OverPos  Pos
 	OverExpr Expr
+	Trivia   *Trivia
 }
 
 func (w *WindowFunctionExpr) Pos() Pos {
@@ -1767,7 +1967,7 @@ func (w *WindowFunctionExpr) String(level int) string {
 	builder.WriteString(w.Function.String(level))
 	builder.WriteString(" OVER ")
 	builder.WriteString(w.OverExpr.String(level))
-	return builder.String()
+	return w.Trivia.format(builder.String(), level, "", false)
 }
 
 type Column struct {
@@ -1785,6 +1985,7 @@ type Column struct {
 
 	Comment          *StringLiteral
 	CompressionCodec *Ident
+	Trivia           *Trivia
 }
 
 func (c *Column) Pos() Pos {
@@ -1796,6 +1997,10 @@ func (c *Column) End() Pos {
 }
 
 func (c *Column) String(level int) string {
+	return c.Trivia.format(c.definition(level), level, "", false)
+}
+
+func (c *Column) definition(level int) string {
 	var builder strings.Builder
 	builder.WriteString(c.Name.String(level))
 	if c.Type != nil {
@@ -1985,6 +2190,7 @@ type NumberLiteral struct {
 	NumEnd  Pos
 	Literal string
 	Base    int
+	Trivia  *Trivia
 }
 
 func (n *NumberLiteral) Pos() Pos {
@@ -1995,14 +2201,22 @@ func (n *NumberLiteral) End() Pos {
 	return n.NumEnd
 }
 
-func (n *NumberLiteral) String(int) string {
-	return n.Literal
+func (n *NumberLiteral) String(level int) string {
+	return n.Trivia.format(n.Literal, level, "", false)
 }
 
 type StringLiteral struct {
 	LiteralPos Pos
 	LiteralEnd Pos
-	Literal    string
+	// Literal is the decoded value of the string.
+	Literal string
+	// Raw is the source text between the quotes, with escape sequences
+	// as written. It is empty for strings that were not parsed.
+	Raw string
+	// Heredoc is the delimiter, e.g. $$ or $body$, of a heredoc string,
+	// and empty for quoted strings.
+	Heredoc string
+	Trivia  *Trivia
 }
 
 func (s *StringLiteral) Pos() Pos {
@@ -2013,8 +2227,106 @@ func (s *StringLiteral) End() Pos {
 	return s.LiteralEnd
 }
 
-func (s *StringLiteral) String(int) string {
-	return "'" + s.Literal + "'"
+func (s *StringLiteral) String(level int) string {
+	literal := quoteString(s.Literal, '\'')
+	if s.Heredoc != "" && !strings.Contains(s.Literal, s.Heredoc) {
+		literal = s.Heredoc + s.Literal + s.Heredoc
+	}
+	return s.Trivia.format(literal, level, "", false)
+}
+
+// TypedLiteral is a string literal with a type, like DATE '2024-01-01'
+// or TIMESTAMP '2024-01-01 00:00:00'.
+type TypedLiteral struct {
+	Type   *Ident
+	Value  *StringLiteral
+	Trivia *Trivia
+}
+
+func (t *TypedLiteral) Pos() Pos {
+	return t.Type.NamePos
+}
+
+func (t *TypedLiteral) End() Pos {
+	return t.Value.End()
+}
+
+func (t *TypedLiteral) String(level int) string {
+	return t.Trivia.format(t.Type.String(level)+" "+t.Value.String(level), level, "", false)
+}
+
+// TupleLiteral is a tuple like (1, 'x'), the empty tuple () or the
+// one-element tuple (1,). A single expression in parentheses, like (1),
+// is a ParamExprList.
+type TupleLiteral struct {
+	LeftParenPos  Pos
+	RightParenPos Pos
+	Items         *ColumnExprList
+	Trivia        *Trivia
+}
+
+func (t *TupleLiteral) Pos() Pos {
+	return t.LeftParenPos
+}
+
+func (t *TupleLiteral) End() Pos {
+	return t.RightParenPos + 1
+}
+
+func (t *TupleLiteral) String(level int) string {
+	if len(t.Items.Items) == 1 {
+		// the comma tells a one-element tuple from an expression in parentheses
+		return t.Trivia.format("("+t.Items.String(level)+",)", level, "", false)
+	}
+	return t.Trivia.format("("+t.Items.String(level)+")", level, "", false)
+}
+
+// MapLiteral is a map like {'a': 1, 'b': 2}.
+type MapLiteral struct {
+	LeftBracePos  Pos
+	RightBracePos Pos
+	Entries       []*MapEntry
+	Trivia        *Trivia
+}
+
+func (m *MapLiteral) Pos() Pos {
+	return m.LeftBracePos
+}
+
+func (m *MapLiteral) End() Pos {
+	return m.RightBracePos + 1
+}
+
+func (m *MapLiteral) String(level int) string {
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for i, entry := range m.Entries {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(entry.String(level))
+	}
+	builder.WriteByte('}')
+	return m.Trivia.format(builder.String(), level, "", false)
+}
+
+// MapEntry is a key and its value in a MapLiteral.
+type MapEntry struct {
+	Key      Expr
+	ColonPos Pos
+	Value    Expr
+}
+
+func (m *MapEntry) Pos() Pos {
+	return m.Key.Pos()
+}
+
+func (m *MapEntry) End() Pos {
+	return m.Value.End()
+}
+
+func (m *MapEntry) String(level int) string {
+	return m.Key.String(level) + ": " + m.Value.String(level)
 }
 
 type RatioExpr struct {
@@ -2094,6 +2406,7 @@ type IntervalExpr struct {
 	IntervalPos Pos
 	Expr        Expr
 	Unit        *Ident
+	Trivia      *Trivia
 }
 
 func (i *IntervalExpr) Pos() Pos {
@@ -2110,7 +2423,7 @@ func (i *IntervalExpr) String(level int) string {
 	builder.WriteString(i.Expr.String(level))
 	builder.WriteByte(' ')
 	builder.WriteString(i.Unit.String(level))
-	return builder.String()
+	return i.Trivia.format(builder.String(), level, "", false)
 }
 
 type EngineExpr struct {
@@ -2145,27 +2458,27 @@ func (e *EngineExpr) String(level int) string {
 	}
 	if e.PrimaryKey != nil {
 		builder.WriteString(NewLine(level))
-		builder.WriteString(e.PrimaryKey.String(level + 1))
+		builder.WriteString(formatClause(e.PrimaryKey, level))
 	}
 	if e.PartitionBy != nil {
 		builder.WriteString(NewLine(level))
-		builder.WriteString(e.PartitionBy.String(level + 1))
+		builder.WriteString(formatClause(e.PartitionBy, level))
 	}
 	if e.SampleBy != nil {
 		builder.WriteString(NewLine(level))
-		builder.WriteString(e.SampleBy.String(level + 1))
+		builder.WriteString(formatClause(e.SampleBy, level))
 	}
 	if e.TTLExprList != nil {
 		builder.WriteString(NewLine(level))
-		builder.WriteString(e.TTLExprList.String(level + 1))
+		builder.WriteString(formatClause(e.TTLExprList, level))
 	}
 	if e.SettingsExprList != nil {
 		builder.WriteString(NewLine(level))
-		builder.WriteString(e.SettingsExprList.String(level + 1))
+		builder.WriteString(formatClause(e.SettingsExprList, level))
 	}
 	if e.OrderByListExpr != nil {
 		builder.WriteString(NewLine(level))
-		builder.WriteString(e.OrderByListExpr.String(level + 1))
+		builder.WriteString(formatClause(e.OrderByListExpr, level))
 	}
 	return builder.String()
 }
@@ -2186,34 +2499,6 @@ func (c *ColumnTypeExpr) String(level int) string {
 	return c.Name.String(level)
 }
 
-type ColumnArgList struct {
-	Distinct      bool
-	LeftParenPos  Pos
-	RightParenPos Pos
-	Items         []Expr
-}
-
-func (c *ColumnArgList) Pos() Pos {
-	return c.LeftParenPos
-}
-
-func (c *ColumnArgList) End() Pos {
-	return c.RightParenPos
-}
-
-func (c *ColumnArgList) String(level int) string {
-	var builder strings.Builder
-	builder.WriteByte('(')
-	for i, item := range c.Items {
-		if i > 0 {
-			builder.WriteByte(',')
-		}
-		builder.WriteString(item.String(level))
-	}
-	builder.WriteByte(')')
-	return builder.String()
-}
-
 type ColumnExprList struct {
 	ListPos     Pos
 	ListEnd     Pos
@@ -2235,10 +2520,18 @@ func (c *ColumnExprList) String(level int) string {
 		builder.WriteString("DISTINCT ")
 	}
 	for i, item := range c.Items {
-		builder.WriteString(item.String(level))
-		if i != len(c.Items)-1 {
-			builder.WriteString(", ")
+		separator := ","
+		if i == len(c.Items)-1 {
+			separator = ""
+		}
+		if i > 0 && !strings.HasSuffix(builder.String(), NewLine(level+1)) {
+			if trivia := attachedTrivia(item); trivia != nil && len(trivia.Leading) > 0 {
+				builder.WriteString(NewLine(level + 1))
+			} else {
+				builder.WriteByte(' ')
+			}
 		}
+		builder.WriteString(formatItem(item, level, separator, false))
 	}
 	return builder.String()
 }
@@ -2286,6 +2579,7 @@ type CaseExpr struct {
 	Whens   []*WhenExpr
 	ElsePos Pos
 	Else    Expr
+	Trivia  *Trivia
 }
 
 func (c *CaseExpr) Pos() Pos {
@@ -2312,7 +2606,7 @@ func (c *CaseExpr) String(level int) string {
 	}
 	builder.WriteString(NewLine(level))
 	builder.WriteString("END")
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type CastExpr struct {
@@ -2320,6 +2614,7 @@ type CastExpr struct {
 	Expr    Expr
 	AsPos   Pos
 	AsType  Expr
+	Trivia  *Trivia
 }
 
 func (c *CastExpr) Pos() Pos {
@@ -2340,7 +2635,7 @@ func (c *CastExpr) String(level int) string {
 	builder.WriteString(c.AsType.String(level))
 	builder.WriteString(NewLine(level + 1))
 	builder.WriteByte(')')
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type WithExpr struct {
@@ -2405,6 +2700,7 @@ type CreateLiveView struct {
 	TableSchema  *TableSchemaExpr
 	WithTimeout  *WithTimeoutExpr
 	SubQuery     *SubQueryExpr
+	Trivia       *Trivia
 }
 
 func (c *CreateLiveView) Type() string {
@@ -2451,13 +2747,16 @@ func (c *CreateLiveView) String(level int) string {
 		builder.WriteString(c.SubQuery.String(level))
 	}
 
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type WithTimeoutExpr struct {
 	WithTimeoutPos Pos
-	Expr           Expr
-	Number         *NumberLiteral
+	// TimeoutEnd is the end of the TIMEOUT keyword, which is the end of
+	// the node if Number is omitted.
+	TimeoutEnd Pos
+	Expr       Expr
+	Number     *NumberLiteral
 }
 
 func (w *WithTimeoutExpr) Pos() Pos {
@@ -2465,13 +2764,19 @@ func (w *WithTimeoutExpr) Pos() Pos {
 }
 
 func (w *WithTimeoutExpr) End() Pos {
-	return w.Number.End()
+	if w.Number != nil {
+		return w.Number.End()
+	}
+	return w.TimeoutEnd
 }
 
 func (w *WithTimeoutExpr) String(int) string {
 	var builder strings.Builder
-	builder.WriteString("WITH TIMEOUT ")
-	builder.WriteString(w.Number.String(0))
+	builder.WriteString("WITH TIMEOUT")
+	if w.Number != nil {
+		builder.WriteByte(' ')
+		builder.WriteString(w.Number.String(0))
+	}
 	return builder.String()
 }
 
@@ -2481,6 +2786,7 @@ type TableExpr struct {
 	Alias    *AliasExpr
 	Expr     Expr
 	HasFinal bool
+	Trivia   *Trivia
 }
 
 func (t *TableExpr) Pos() Pos {
@@ -2501,12 +2807,13 @@ func (t *TableExpr) String(level int) string {
 	if t.HasFinal {
 		builder.WriteString(" FINAL")
 	}
-	return builder.String()
+	return t.Trivia.format(builder.String(), level, "", false)
 }
 
 type OnExpr struct {
-	OnPos Pos
-	On    *ColumnExprList
+	OnPos  Pos
+	On     *ColumnExprList
+	Trivia *Trivia
 }
 
 func (o *OnExpr) Pos() Pos {
@@ -2521,12 +2828,13 @@ func (o *OnExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString("ON ")
 	builder.WriteString(o.On.String(level))
-	return builder.String()
+	return o.Trivia.format(builder.String(), level, "", false)
 }
 
 type UsingExpr struct {
 	UsingPos Pos
 	Using    *ColumnExprList
+	Trivia   *Trivia
 }
 
 func (u *UsingExpr) Pos() Pos {
@@ -2541,7 +2849,7 @@ func (u *UsingExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString("USING ")
 	builder.WriteString(u.Using.String(level))
-	return builder.String()
+	return u.Trivia.format(builder.String(), level, "", false)
 }
 
 type JoinExpr struct {
@@ -2551,6 +2859,7 @@ type JoinExpr struct {
 	Modifiers   []string
 	SampleRatio *SampleRatioExpr
 	Constraints Expr
+	Trivia      *Trivia
 }
 
 func (j *JoinExpr) Pos() Pos {
@@ -2558,31 +2867,36 @@ func (j *JoinExpr) Pos() Pos {
 }
 
 func (j *JoinExpr) End() Pos {
-	return j.Left.End()
+	if j.Constraints != nil {
+		return j.Constraints.End()
+	}
+	return j.Right.End()
 }
 
 func (j *JoinExpr) String(level int) string {
 	var builder strings.Builder
-	builder.WriteString(j.Left.String(level))
+	left, right := beforeOperator(j.Left.String(level), j.Right, level)
 	if len(j.Modifiers) != 0 {
-		builder.WriteByte(' ')
+		builder.WriteString(left)
 		builder.WriteString(strings.Join(j.Modifiers, " "))
 	} else {
+		builder.WriteString(strings.TrimSuffix(left, " "))
 		builder.WriteString(",")
 	}
 	builder.WriteByte(' ')
-	builder.WriteString(j.Right.String(level))
+	builder.WriteString(right.String(level))
 	if j.Constraints != nil {
 		builder.WriteByte(' ')
 		builder.WriteString(j.Constraints.String(level))
 	}
-	return builder.String()
+	return j.Trivia.format(builder.String(), level, "", false)
 }
 
 type JoinConstraintExpr struct {
 	ConstraintPos Pos
 	On            *ColumnExprList
 	Using         *ColumnExprList
+	Trivia        *Trivia
 }
 
 func (j *JoinConstraintExpr) Pos() Pos {
@@ -2605,12 +2919,13 @@ func (j *JoinConstraintExpr) String(level int) string {
 		builder.WriteString("USING ")
 		builder.WriteString(j.Using.String(level))
 	}
-	return builder.String()
+	return j.Trivia.format(builder.String(), level, "", false)
 }
 
 type FromExpr struct {
 	FromPos Pos
 	Expr    Expr
+	Trivia  *Trivia
 }
 
 func (f *FromExpr) Pos() Pos {
@@ -2626,12 +2941,13 @@ func (f *FromExpr) String(level int) string {
 	builder.WriteString("FROM")
 	builder.WriteString(NewLine(level + 1))
 	builder.WriteString(f.Expr.String(level + 1))
-	return builder.String()
+	return f.Trivia.format(builder.String(), level, "", false)
 }
 
 type IsNullExpr struct {
-	IsPos Pos
-	Expr  Expr
+	IsPos  Pos
+	Expr   Expr
+	Trivia *Trivia
 }
 
 func (n *IsNullExpr) Pos() Pos {
@@ -2646,12 +2962,13 @@ func (n *IsNullExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString(n.Expr.String(level))
 	builder.WriteString(" IS NULL")
-	return builder.String()
+	return n.Trivia.format(builder.String(), level, "", false)
 }
 
 type IsNotNullExpr struct {
-	IsPos Pos
-	Expr  Expr
+	IsPos  Pos
+	Expr   Expr
+	Trivia *Trivia
 }
 
 func (n *IsNotNullExpr) Pos() Pos {
@@ -2666,13 +2983,14 @@ func (n *IsNotNullExpr) String(level int) string {
 	var builder strings.Builder
 	builder.WriteString(n.Expr.String(level))
 	builder.WriteString(" IS NOT NULL")
-	return builder.String()
+	return n.Trivia.format(builder.String(), level, "", false)
 }
 
 type AliasExpr struct {
 	Expr     Expr
 	AliasPos Pos
 	Alias    *Ident
+	Trivia   *Trivia
 }
 
 func (a *AliasExpr) Pos() Pos {
@@ -2694,12 +3012,13 @@ func (a *AliasExpr) String(level int) string {
 	}
 	builder.WriteString(" AS ")
 	builder.WriteString(a.Alias.String(level))
-	return builder.String()
+	return a.Trivia.format(builder.String(), level, "", false)
 }
 
 type WhereExpr struct {
 	WherePos Pos
 	Expr     Expr
+	Trivia   *Trivia
 }
 
 func (w *WhereExpr) Pos() Pos {
@@ -2715,12 +3034,13 @@ func (w *WhereExpr) String(level int) string {
 	builder.WriteString("WHERE")
 	builder.WriteString(NewLine(level + 1))
 	builder.WriteString(w.Expr.String(level))
-	return builder.String()
+	return w.Trivia.format(builder.String(), level, "", false)
 }
 
 type PrewhereExpr struct {
 	PrewherePos Pos
 	Expr        Expr
+	Trivia      *Trivia
 }
 
 func (w *PrewhereExpr) Pos() Pos {
@@ -2732,7 +3052,7 @@ func (w *PrewhereExpr) End() Pos {
 }
 
 func (w *PrewhereExpr) String(level int) string {
-	return "PREWHERE " + w.Expr.String(level+1)
+	return w.Trivia.format("PREWHERE "+w.Expr.String(level+1), level, "", false)
 }
 
 type GroupByExpr struct {
@@ -2742,6 +3062,7 @@ type GroupByExpr struct {
 	WithCube      bool
 	WithRollup    bool
 	WithTotals    bool
+	Trivia        *Trivia
 }
 
 func (g *GroupByExpr) Pos() Pos {
@@ -2772,12 +3093,13 @@ func (g *GroupByExpr) String(level int) string {
 	if g.WithTotals {
 		builder.WriteString(" WITH TOTALS")
 	}
-	return builder.String()
+	return g.Trivia.format(builder.String(), level, "", false)
 }
 
 type HavingExpr struct {
 	HavingPos Pos
 	Expr      Expr
+	Trivia    *Trivia
 }
 
 func (h *HavingExpr) Pos() Pos {
@@ -2789,13 +3111,14 @@ func (h *HavingExpr) End() Pos {
 }
 
 func (h *HavingExpr) String(level int) string {
-	return "HAVING " + h.Expr.String(level)
+	return h.Trivia.format("HAVING "+h.Expr.String(level), level, "", false)
 }
 
 type LimitExpr struct {
 	LimitPos Pos
 	Limit    Expr
 	Offset   Expr
+	Trivia   *Trivia
 }
 
 func (l *LimitExpr) Pos() Pos {
@@ -2817,12 +3140,13 @@ func (l *LimitExpr) String(level int) string {
 		builder.WriteString(" OFFSET ")
 		builder.WriteString(l.Offset.String(level))
 	}
-	return builder.String()
+	return l.Trivia.format(builder.String(), level, "", false)
 }
 
 type LimitByExpr struct {
 	Limit  *LimitExpr
 	ByExpr *ColumnExprList
+	Trivia *Trivia
 }
 
 func (l *LimitByExpr) Pos() Pos {
@@ -2851,7 +3175,7 @@ func (l *LimitByExpr) String(level int) string {
 		builder.WriteString(" BY ")
 		builder.WriteString(l.ByExpr.String(level))
 	}
-	return builder.String()
+	return l.Trivia.format(builder.String(), level, "", false)
 }
 
 type WindowConditionExpr struct {
@@ -3037,6 +3361,7 @@ type ArrayJoinExpr struct {
 	ArrayPos Pos
 	Type     string
 	Expr     Expr
+	Trivia   *Trivia
 }
 
 func (a *ArrayJoinExpr) Pos() Pos {
@@ -3048,7 +3373,7 @@ func (a *ArrayJoinExpr) End() Pos {
 }
 
 func (a *ArrayJoinExpr) String(level int) string {
-	return a.Type + " ARRAY JOIN " + a.Expr.String(level)
+	return a.Trivia.format(a.Type+" ARRAY JOIN "+a.Expr.String(level), level, "", false)
 }
 
 type SelectQuery struct {
@@ -3072,6 +3397,7 @@ type SelectQuery struct {
 	UnionAll      *SelectQuery
 	UnionDistinct *SelectQuery
 	Except        *SelectQuery
+	Trivia        *Trivia
 }
 
 func (s *SelectQuery) Pos() Pos {
@@ -3103,70 +3429,75 @@ func (s *SelectQuery) String(level int) string { // nolint: funlen
 	}
 	columns := s.SelectColumns.Items
 	for i, column := range columns {
-		builder.WriteString(NewLine(level + 1))
-		builder.WriteString(column.String(level))
-		if i != len(columns)-1 {
-			builder.WriteByte(',')
+		separator := ","
+		if i == len(columns)-1 {
+			separator = ""
 		}
+		builder.WriteString(NewLine(level + 1))
+		builder.WriteString(formatItem(column, level, separator, true))
 	}
+	columnsEnd := builder.Len()
 	if s.From != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.From.String(level))
 	}
 	if s.ArrayJoin != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.ArrayJoin.String(level))
 	}
 	if s.Window != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.Window.String(level))
 	}
 	if s.Prewhere != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.Prewhere.String(level))
 	}
 	if s.Where != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.Where.String(level))
 	}
 	if s.GroupBy != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.GroupBy.String(level))
 	}
 	if s.Having != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.Having.String(level))
 	}
 	if s.OrderBy != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.OrderBy.String(level))
 	}
 	if s.LimitBy != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.LimitBy.String(level))
 	}
 	if s.Limit != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.Limit.String(level))
 	}
 	if s.Settings != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(s.Settings.String(level))
 	}
 	if s.UnionAll != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(" UNION ALL ")
 		builder.WriteString(s.UnionAll.String(level))
 	} else if s.UnionDistinct != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(" UNION DISTINCT ")
 		builder.WriteString(s.UnionDistinct.String(level))
 	} else if s.Except != nil {
-		builder.WriteString(NewLine(level))
+		lineBreak(&builder, level)
 		builder.WriteString(" EXCEPT ")
 		builder.WriteString(s.Except.String(level))
 	}
-	return builder.String()
+	if builder.Len() == columnsEnd && len(columns) > 0 && attachedTrivia(columns[len(columns)-1]).endsWithLineComment() {
+		lineBreak(&builder, level)
+	}
+	return s.Trivia.format(builder.String(), level, "", false)
 }
 
 type SubQueryExpr struct {
@@ -3194,6 +3525,7 @@ func (s *SubQueryExpr) String(level int) string {
 type NotExpr struct {
 	NotPos Pos
 	Expr   Expr
+	Trivia *Trivia
 }
 
 func (n *NotExpr) Pos() Pos {
@@ -3205,12 +3537,13 @@ func (n *NotExpr) End() Pos {
 }
 
 func (n *NotExpr) String(level int) string {
-	return "NOT " + n.Expr.String(level+1)
+	return n.Trivia.format("NOT "+n.Expr.String(level+1), level, "", false)
 }
 
 type NegateExpr struct {
 	NegatePos Pos
 	Expr      Expr
+	Trivia    *Trivia
 }
 
 func (n *NegateExpr) Pos() Pos {
@@ -3222,12 +3555,13 @@ func (n *NegateExpr) End() Pos {
 }
 
 func (n *NegateExpr) String(level int) string {
-	return "-" + n.Expr.String(level+1)
+	return n.Trivia.format("-"+n.Expr.String(level+1), level, "", false)
 }
 
 type GlobalInExpr struct {
 	GlobalPos Pos
 	Expr      Expr
+	Trivia    *Trivia
 }
 
 func (g *GlobalInExpr) Pos() Pos {
@@ -3239,7 +3573,7 @@ func (g *GlobalInExpr) End() Pos {
 }
 
 func (g *GlobalInExpr) String(level int) string {
-	return "GLOBAL " + g.Expr.String(level+1)
+	return g.Trivia.format("GLOBAL "+g.Expr.String(level+1), level, "", false)
 }
 
 type ExtractExpr struct {
@@ -3247,6 +3581,7 @@ type ExtractExpr struct {
 	Interval   *Ident
 	FromPos    Pos
 	FromExpr   Expr
+	Trivia     *Trivia
 }
 
 func (e *ExtractExpr) Pos() Pos {
@@ -3264,7 +3599,7 @@ func (e *ExtractExpr) String(level int) string {
 	builder.WriteString(" FROM ")
 	builder.WriteString(e.FromExpr.String(level))
 	builder.WriteByte(')')
-	return builder.String()
+	return e.Trivia.format(builder.String(), level, "", false)
 }
 
 type DropDatabase struct {
@@ -3273,6 +3608,7 @@ type DropDatabase struct {
 	Name         *Ident
 	IfExists     bool
 	OnCluster    *OnClusterExpr
+	Trivia       *Trivia
 }
 
 func (d *DropDatabase) Pos() Pos {
@@ -3298,7 +3634,7 @@ func (d *DropDatabase) String(level int) string {
 		builder.WriteString(NewLine(level + 1))
 		builder.WriteString(d.OnCluster.String(level))
 	}
-	return builder.String()
+	return d.Trivia.format(builder.String(), level, "", false)
 }
 
 type DropStmt struct {
@@ -3311,6 +3647,7 @@ type DropStmt struct {
 	OnCluster   *OnClusterExpr
 	IsTemporary bool
 	Modifier    string
+	Trivia      *Trivia
 }
 
 func (d *DropStmt) Pos() Pos {
@@ -3343,7 +3680,7 @@ func (d *DropStmt) String(level int) string {
 	if len(d.Modifier) != 0 {
 		builder.WriteString(" " + d.Modifier)
 	}
-	return builder.String()
+	return d.Trivia.format(builder.String(), level, "", false)
 }
 
 type DropUserOrRole struct {
@@ -3354,6 +3691,7 @@ type DropUserOrRole struct {
 	IfExists     bool
 	Modifier     string
 	From         *Ident
+	Trivia       *Trivia
 }
 
 func (d *DropUserOrRole) Pos() Pos {
@@ -3387,13 +3725,14 @@ func (d *DropUserOrRole) String(level int) string {
 		builder.WriteString(" FROM ")
 		builder.WriteString(d.From.String(level))
 	}
-	return builder.String()
+	return d.Trivia.format(builder.String(), level, "", false)
 }
 
 type UseExpr struct {
 	UsePos       Pos
 	StatementEnd Pos
 	Database     *Ident
+	Trivia       *Trivia
 }
 
 func (u *UseExpr) Pos() Pos {
@@ -3405,7 +3744,7 @@ func (u *UseExpr) End() Pos {
 }
 
 func (u *UseExpr) String(level int) string {
-	return "USE " + u.Database.String(level+1)
+	return u.Trivia.format("USE "+u.Database.String(level+1), level, "", false)
 }
 
 type CTEExpr struct {
@@ -3439,6 +3778,7 @@ func (c *CTEExpr) String(level int) string {
 type SetExpr struct {
 	SetPos   Pos
 	Settings *SettingsExprList
+	Trivia   *Trivia
 }
 
 func (s *SetExpr) Pos() Pos {
@@ -3458,7 +3798,7 @@ func (s *SetExpr) String(level int) string {
 		}
 		builder.WriteString(item.String(level))
 	}
-	return builder.String()
+	return s.Trivia.format(builder.String(), level, "", false)
 }
 
 type FormatExpr struct {
@@ -3486,6 +3826,7 @@ type OptimizeExpr struct {
 	Partition    *PartitionExpr
 	HasFinal     bool
 	Deduplicate  *DeduplicateExpr
+	Trivia       *Trivia
 }
 
 func (o *OptimizeExpr) Pos() Pos {
@@ -3514,7 +3855,7 @@ func (o *OptimizeExpr) String(level int) string {
 	if o.Deduplicate != nil {
 		builder.WriteString(o.Deduplicate.String(level))
 	}
-	return builder.String()
+	return o.Trivia.format(builder.String(), level, "", false)
 }
 
 type DeduplicateExpr struct {
@@ -3553,6 +3894,7 @@ func (d *DeduplicateExpr) String(level int) string {
 type SystemExpr struct {
 	SystemPos Pos
 	Expr      Expr
+	Trivia    *Trivia
 }
 
 func (s *SystemExpr) Pos() Pos {
@@ -3564,7 +3906,7 @@ func (s *SystemExpr) End() Pos {
 }
 
 func (s *SystemExpr) String(level int) string {
-	return "SYSTEM " + s.Expr.String(level)
+	return s.Trivia.format("SYSTEM "+s.Expr.String(level), level, "", false)
 }
 
 type SystemFlushExpr struct {
@@ -3692,6 +4034,7 @@ type TruncateTable struct {
 	IfExists     bool
 	Name         *TableIdentifier
 	OnCluster    *OnClusterExpr
+	Trivia       *Trivia
 }
 
 func (t *TruncateTable) Pos() Pos {
@@ -3721,7 +4064,7 @@ func (t *TruncateTable) String(level int) string {
 		builder.WriteString(NewLine(level))
 		builder.WriteString(t.OnCluster.String(level))
 	}
-	return builder.String()
+	return t.Trivia.format(builder.String(), level, "", false)
 }
 
 type SampleRatioExpr struct {
@@ -3757,6 +4100,7 @@ type DeleteFromExpr struct {
 	Table     *TableIdentifier
 	OnCluster *OnClusterExpr
 	WhereExpr Expr
+	Trivia    *Trivia
 }
 
 func (d *DeleteFromExpr) Pos() Pos {
@@ -3780,7 +4124,7 @@ func (d *DeleteFromExpr) String(level int) string {
 		builder.WriteString("WHERE ")
 		builder.WriteString(d.WhereExpr.String(level))
 	}
-	return builder.String()
+	return d.Trivia.format(builder.String(), level, "", false)
 }
 
 type ColumnNamesExpr struct {
@@ -3844,6 +4188,7 @@ type InsertExpr struct {
 	ColumnNames *ColumnNamesExpr
 	Values      []*ValuesExpr
 	SelectExpr  *SelectQuery
+	Trivia      *Trivia
 }
 
 func (i *InsertExpr) Pos() Pos {
@@ -3854,7 +4199,16 @@ func (i *InsertExpr) End() Pos {
 	if i.SelectExpr != nil {
 		return i.SelectExpr.End()
 	}
-	return i.Values[len(i.Values)-1].End()
+	if len(i.Values) > 0 {
+		return i.Values[len(i.Values)-1].End()
+	}
+	if i.Format != nil {
+		return i.Format.End()
+	}
+	if i.ColumnNames != nil {
+		return i.ColumnNames.End()
+	}
+	return i.Table.End()
 }
 
 func (i *InsertExpr) String(level int) string {
@@ -3883,13 +4237,14 @@ func (i *InsertExpr) String(level int) string {
 			builder.WriteString(value.String(level))
 		}
 	}
-	return builder.String()
+	return i.Trivia.format(builder.String(), level, "", false)
 }
 
 type CheckExpr struct {
 	CheckPos  Pos
 	Table     *TableIdentifier
 	Partition *PartitionExpr
+	Trivia    *Trivia
 }
 
 func (c *CheckExpr) Pos() Pos {
@@ -3897,7 +4252,10 @@ func (c *CheckExpr) Pos() Pos {
 }
 
 func (c *CheckExpr) End() Pos {
-	return c.Partition.End()
+	if c.Partition != nil {
+		return c.Partition.End()
+	}
+	return c.Table.End()
 }
 
 func (c *CheckExpr) String(level int) string {
@@ -3908,13 +4266,14 @@ func (c *CheckExpr) String(level int) string {
 	if c.Partition != nil {
 		builder.WriteString(c.Partition.String(level))
 	}
-	return builder.String()
+	return c.Trivia.format(builder.String(), level, "", false)
 }
 
 type UnaryExpr struct {
 	UnaryPos Pos
 	Kind     TokenKind
 	Expr     Expr
+	Trivia   *Trivia
 }
 
 func (n *UnaryExpr) Pos() Pos {
@@ -3926,7 +4285,7 @@ func (n *UnaryExpr) End() Pos {
 }
 
 func (n *UnaryExpr) String(level int) string {
-	return "-" + n.Expr.String(level+1)
+	return n.Trivia.format("-"+n.Expr.String(level+1), level, "", false)
 }
 
 type RenameStmt struct {
@@ -3936,6 +4295,7 @@ type RenameStmt struct {
 	RenameTarget   string
 	TargetPairList []*TargetPair
 	OnCluster      *OnClusterExpr
+	Trivia         *Trivia
 }
 
 func (r *RenameStmt) Pos() Pos {
@@ -3965,7 +4325,7 @@ func (r *RenameStmt) String(level int) string {
 		builder.WriteString(NewLine(level))
 		builder.WriteString(r.OnCluster.String(level))
 	}
-	return builder.String()
+	return r.Trivia.format(builder.String(), level, "", false)
 }
 
 type TargetPair struct {
@@ -3981,7 +4341,7 @@ func (t *TargetPair) End() Pos {
 	return t.New.End()
 }
 
-func (t *TargetPair) String() string {
+func (t *TargetPair) String(int) string {
 	return t.Old.String(0) + " TO " + t.New.String(0)
 }
 
@@ -3989,6 +4349,7 @@ type ExplainExpr struct {
 	ExplainPos Pos
 	Type       string
 	Statement  Expr
+	Trivia     *Trivia
 }
 
 func (e *ExplainExpr) Pos() Pos {
@@ -4005,7 +4366,7 @@ func (e *ExplainExpr) String(level int) string {
 	builder.WriteString(e.Type)
 	builder.WriteByte(' ')
 	builder.WriteString(e.Statement.String(level))
-	return builder.String()
+	return e.Trivia.format(builder.String(), level, "", false)
 }
 
 type PrivilegeExpr struct {
@@ -4045,6 +4406,7 @@ type GrantPrivilegeExpr struct {
 	On           *TableIdentifier
 	To           []*Ident
 	WithOptions  []string
+	Trivia       *Trivia
 }
 
 func (g *GrantPrivilegeExpr) Pos() Pos {
@@ -4085,5 +4447,26 @@ func (g *GrantPrivilegeExpr) String(level int) string {
 		builder.WriteString(" WITH " + option + " OPTION")
 	}
 
-	return builder.String()
+	return g.Trivia.format(builder.String(), level, "", false)
+}
+
+// BadStatement is a placeholder for a statement that failed to parse
+// in Parser.ParseStatementsWithRecovery. Text holds its source text.
+type BadStatement struct {
+	StatementPos Pos
+	StatementEnd Pos
+	Text         string
+	Trivia       *Trivia
+}
+
+func (b *BadStatement) Pos() Pos {
+	return b.StatementPos
+}
+
+func (b *BadStatement) End() Pos {
+	return b.StatementEnd
+}
+
+func (b *BadStatement) String(level int) string {
+	return b.Trivia.format(b.Text, level, "", false)
 }
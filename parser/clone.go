@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"reflect"
+)
+
+var (
+	posType    = reflect.TypeOf(Pos(0))
+	triviaType = reflect.TypeOf((*Trivia)(nil))
+	exprType   = reflect.TypeOf((*Expr)(nil)).Elem()
+)
+
+// Clone returns a deep copy of the tree rooted at node. The copy shares
+// no pointers, slices or maps with the original, so it can be modified
+// (e.g. with Apply) without affecting the source tree.
+func Clone(node Expr) Expr {
+	if isNilExpr(node) {
+		return node
+	}
+	c := cloner{seen: make(map[uintptr]reflect.Value)}
+	return c.clone(reflect.ValueOf(node)).Interface().(Expr)
+}
+
+type cloner struct {
+	// seen maps an original pointer to its copy so that a node
+	// referenced twice in the tree is also shared in the copy.
+	seen map[uintptr]reflect.Value
+}
+
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if copied, ok := c.seen[v.Pointer()]; ok && copied.Type() == v.Type() {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		c.seen[v.Pointer()] = copied
+		copied.Elem().Set(c.clone(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(c.clone(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			copied.Field(i).Set(c.clone(v.Field(i)))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(c.clone(iter.Key()), c.clone(iter.Value()))
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// EqualOptions controls how Equal compares two trees.
+type EqualOptions struct {
+	// IgnorePos skips all Pos fields, so that trees parsed from
+	// differently formatted SQL compare equal.
+	IgnorePos bool
+	// IgnoreComments skips the comments attached to statements and columns.
+	IgnoreComments bool
+}
+
+// Equal reports whether a and b are structurally equal: they have the
+// same node types and the same field values throughout the tree.
+func Equal(a, b Expr, opts EqualOptions) bool {
+	if isNilExpr(a) || isNilExpr(b) {
+		return isNilExpr(a) && isNilExpr(b)
+	}
+	return equalValue(reflect.ValueOf(a), reflect.ValueOf(b), opts)
+}
+
+func equalValue(a, b reflect.Value, opts EqualOptions) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+	if opts.IgnorePos && a.Type() == posType || opts.IgnoreComments && a.Type() == triviaType {
+		return true
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Kind() == reflect.Interface && a.Elem().Type() != b.Elem().Type() {
+			return false
+		}
+		return equalValue(a.Elem(), b.Elem(), opts)
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !equalValue(a.Field(i), b.Field(i), opts) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalValue(a.Index(i), b.Index(i), opts) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		iter := a.MapRange()
+		for iter.Next() {
+			other := b.MapIndex(iter.Key())
+			if !other.IsValid() || !equalValue(iter.Value(), other, opts) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Interface() == b.Interface()
+	}
+}
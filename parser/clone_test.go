@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	for name, stmts := range parseTestdataStatements(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, stmt := range stmts {
+				cloned := Clone(stmt)
+				require.True(t, Equal(stmt, cloned, EqualOptions{}))
+				require.Equal(t, stmt.String(0), cloned.String(0))
+
+				original := make(map[Expr]int)
+				collectNodes(reflect.ValueOf(stmt), original)
+				copied := make(map[Expr]int)
+				collectNodes(reflect.ValueOf(cloned), copied)
+				require.Equal(t, len(original), len(copied))
+				for node := range copied {
+					_, shared := original[node]
+					require.Falsef(t, shared, "node %T is shared with the original", node)
+				}
+			}
+		})
+	}
+}
+
+func TestClone_Independent(t *testing.T) {
+	stmt := parseSingleStatement(t, "SELECT a, b FROM db.t1 WHERE a > 1")
+	expected := stmt.String(0)
+	cloned := Clone(stmt).(*SelectQuery)
+	Inspect(cloned.From, func(node Expr) bool {
+		if table, ok := node.(*TableIdentifier); ok {
+			table.Table.Name = "t2"
+		}
+		return true
+	})
+	cloned.SelectColumns.Items[0] = &NumberLiteral{Literal: "1"}
+
+	require.Equal(t, expected, stmt.String(0))
+	require.Contains(t, cloned.String(0), "db.t2")
+	require.Equal(t, "1, b", cloned.SelectColumns.String(0))
+	require.False(t, Equal(stmt, cloned, EqualOptions{}))
+	require.Nil(t, Clone(nil))
+}
+
+func TestEqual(t *testing.T) {
+	a := parseSingleStatement(t, "SELECT a, b FROM t1 WHERE a > 1")
+	b := parseSingleStatement(t, "SELECT   a,  b\nFROM t1\nWHERE a > 1")
+	c := parseSingleStatement(t, "SELECT a, b FROM t1 WHERE a > 2")
+
+	require.False(t, Equal(a, b, EqualOptions{}))
+	require.True(t, Equal(a, b, EqualOptions{IgnorePos: true}))
+	require.False(t, Equal(a, c, EqualOptions{IgnorePos: true}))
+	require.False(t, Equal(&Ident{Name: "a"}, &StringLiteral{Literal: "a"}, EqualOptions{IgnorePos: true}))
+	require.True(t, Equal(nil, nil, EqualOptions{}))
+	require.False(t, Equal(a, nil, EqualOptions{}))
+}
+
+func TestEqual_FormatRoundTrip(t *testing.T) {
+	for _, sql := range []string{
+		"SELECT a, count(*) AS cnt FROM db.t1 AS x JOIN t2 ON x.id = t2.id WHERE a IN (1, 2) GROUP BY a ORDER BY cnt DESC LIMIT 10",
+		"SELECT toString(b), -a, a IS NOT NULL FROM t1 SETTINGS max_threads = 8",
+		"WITH t AS (SELECT 1 AS v) SELECT v FROM t UNION ALL SELECT 2",
+		"CREATE TABLE IF NOT EXISTS db.t1 (id UInt64, name String COMMENT 'n') ENGINE = MergeTree() ORDER BY id",
+		"ALTER TABLE db.t1 ON CLUSTER c1 DROP COLUMN a, ADD COLUMN b Int32 AFTER c",
+	} {
+		t.Run(sql, func(t *testing.T) {
+			stmt := parseSingleStatement(t, sql)
+			reparsed := parseSingleStatement(t, stmt.String(0))
+			require.True(t, Equal(stmt, reparsed, EqualOptions{IgnorePos: true}))
+		})
+	}
+}
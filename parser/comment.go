@@ -0,0 +1,361 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Comment is a comment in the SQL source. Text includes the comment
+// markers, e.g. "-- note" or "/* note */".
+type Comment struct {
+	CommentPos Pos
+	CommentEnd Pos
+	Text       string
+}
+
+// Trivia holds the comments attached to a node. ParseStatements attaches
+// every comment in the input to the nearest statement, clause or
+// expression, so that String doesn't drop any of them.
+type Trivia struct {
+	// Leading are the comments before the node, up to the previous node.
+	Leading []Comment
+	// Trailing are the comments after the node on the same line, and
+	// comments inside a statement that no node follows.
+	Trailing []Comment
+}
+
+// format returns body surrounded by the comments: each leading comment
+// on its own line, and the trailing comments after separator. A trailing
+// line comment is followed by a newline unless atLineEnd is set, i.e.
+// the caller starts a new line after the node anyway.
+func (t *Trivia) format(body string, level int, separator string, atLineEnd bool) string {
+	if t == nil {
+		return body + separator
+	}
+	var builder strings.Builder
+	for _, comment := range t.Leading {
+		builder.WriteString(comment.Text)
+		builder.WriteString(NewLine(level))
+	}
+	if len(t.Leading) > 0 {
+		body = strings.TrimPrefix(body, NewLine(level))
+	}
+	builder.WriteString(body)
+	builder.WriteString(separator)
+	lineComment := false
+	for _, comment := range t.Trailing {
+		if lineComment {
+			builder.WriteString(NewLine(level))
+		} else {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(comment.Text)
+		lineComment = !strings.HasPrefix(comment.Text, "/*")
+	}
+	if lineComment && !atLineEnd {
+		builder.WriteString(NewLine(level))
+	}
+	return builder.String()
+}
+
+// endsWithLineComment reports whether the last trailing comment is a line
+// comment, after which nothing can be printed on the same line.
+func (t *Trivia) endsWithLineComment() bool {
+	return t != nil && len(t.Trailing) > 0 && !strings.HasPrefix(t.Trailing[len(t.Trailing)-1].Text, "/*")
+}
+
+// formatItem returns a list item followed by separator, with the comments
+// attached to the item around them, so that a trailing comment follows
+// the separator instead of preceding it.
+func formatItem(item Expr, level int, separator string, atLineEnd bool) string {
+	trivia := attachedTrivia(item)
+	if trivia == nil {
+		return item.String(level) + separator
+	}
+	return trivia.format(withTrivia(item, nil).String(level), level+1, separator, atLineEnd)
+}
+
+// beforeOperator returns the text to print from the left operand up to
+// the operator, and the right operand to print after it. The leading
+// comments of the right operand go before the operator, on their own
+// lines, since the operator and the operand are printed on the same line.
+func beforeOperator(left string, right Expr, level int) (string, Expr) {
+	trivia := attachedTrivia(right)
+	if trivia == nil || len(trivia.Leading) == 0 {
+		if strings.HasSuffix(left, NewLine(level)) {
+			return left, right
+		}
+		return left + " ", right
+	}
+	var builder strings.Builder
+	builder.WriteString(strings.TrimSuffix(left, NewLine(level)))
+	for _, comment := range trivia.Leading {
+		builder.WriteString(NewLine(level))
+		builder.WriteString(comment.Text)
+	}
+	builder.WriteString(NewLine(level))
+	return builder.String(), withTrivia(right, &Trivia{Trailing: trivia.Trailing})
+}
+
+// formatClause formats a clause that starts a line at level and indents
+// its continuation lines one level deeper, with its comments at level.
+func formatClause(clause Expr, level int) string {
+	return attachedTrivia(clause).format(withTrivia(clause, nil).String(level+1), level, "", false)
+}
+
+// lineBreak starts a new line, reusing the empty line that a trailing
+// line comment ends with.
+func lineBreak(builder *strings.Builder, level int) {
+	text := builder.String()
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 && strings.TrimSpace(text[i:]) == "" {
+		builder.Reset()
+		builder.WriteString(text[:i])
+	}
+	builder.WriteString(NewLine(level))
+}
+
+// statementSpan is the range of a statement in the input, from its first
+// token up to the terminating ';' or the end of input.
+type statementSpan struct {
+	start Pos
+	end   Pos
+}
+
+// attachComments adds each comment to the Trivia of the node it belongs to:
+// comments before a statement lead it and comments after it on the same
+// line trail it. Comments inside a statement go to the node they follow on
+// the same line, or else to the node that follows them, or to its nearest
+// ancestor if the node doesn't print comments; the remaining ones trail
+// the statement.
+func attachComments(input string, statements []Expr, spans []statementSpan, comments []*Token) {
+	var tokens []Token
+	var carriers []carrier
+	carriersOf := -1
+	i := 0
+	for _, token := range comments {
+		comment := Comment{CommentPos: token.Pos, CommentEnd: token.End, Text: token.String}
+		for i+1 < len(statements) && spans[i+1].start <= comment.CommentPos {
+			i++
+		}
+		if i == len(statements) {
+			return
+		}
+		switch span := spans[i]; {
+		case comment.CommentPos < span.start:
+			addLeading(statements[i], comment)
+		case comment.CommentPos < span.end:
+			if _, ok := statements[i].(*BadStatement); ok {
+				// the comment is already part of the statement text
+				continue
+			}
+			if tokens == nil {
+				all, _ := Tokenize(input)
+				tokens = significantTokens(all)
+			}
+			if carriersOf != i {
+				carriers, carriersOf = carriersIn(tokens, statements[i]), i
+			}
+			attachInnerComment(input, tokens, carriers, statements[i], span.end, comment)
+		case sameLine(input, span.end, comment.CommentPos) || i+1 == len(statements):
+			addTrailing(statements[i], comment)
+		default:
+			addLeading(statements[i+1], comment)
+		}
+	}
+}
+
+// carrier is a node of a statement with the source range of its tokens,
+// and the node that takes the comments next to it.
+type carrier struct {
+	span sourceSpan
+	// printed reports whether the node itself prints its comments.
+	printed bool
+	// target is the node itself if printed, or else its nearest ancestor
+	// that prints its comments, up to the statement.
+	target Expr
+}
+
+// carriersIn returns the nodes of statement in the order Inspect visits
+// them, i.e. outer nodes first.
+func carriersIn(tokens []Token, statement Expr) []carrier {
+	var nodes []Expr
+	var parents, stack []int
+	Inspect(statement, func(node Expr) bool {
+		switch {
+		case node == nil:
+			stack = stack[:len(stack)-1]
+		case node == statement:
+			stack = append(stack, -1)
+		default:
+			nodes = append(nodes, node)
+			parents = append(parents, stack[len(stack)-1])
+			stack = append(stack, len(nodes)-1)
+		}
+		return true
+	})
+	printed := printedNodes(statement, nodes)
+	var carriers []carrier
+	for i, node := range nodes {
+		span, ok := snapSpan(tokens, node.Pos(), node.End())
+		if !ok {
+			continue
+		}
+		target := i
+		for target >= 0 && !printed[target] {
+			target = parents[target]
+		}
+		c := carrier{span: span, printed: printed[i], target: statement}
+		if target >= 0 {
+			c.target = nodes[target]
+		}
+		carriers = append(carriers, c)
+	}
+	return carriers
+}
+
+// printedNodes reports which of the nodes of statement print their comments.
+// Not all of them do: some are printed field by field by their parent, and
+// some not at all. It attaches a marker comment to each node with a Trivia
+// field, prints the statement once and looks for the markers.
+func printedNodes(statement Expr, nodes []Expr) []bool {
+	saved := make([]*Trivia, len(nodes))
+	for i, node := range nodes {
+		if field := triviaField(node); field.IsValid() {
+			saved[i] = field.Interface().(*Trivia)
+			marker := Comment{Text: fmt.Sprintf("/*\x00%d*/", i)}
+			field.Set(reflect.ValueOf(&Trivia{Trailing: []Comment{marker}}))
+		}
+	}
+	text := statement.String(0)
+	printed := make([]bool, len(nodes))
+	for i, node := range nodes {
+		if field := triviaField(node); field.IsValid() {
+			printed[i] = strings.Contains(text, fmt.Sprintf("/*\x00%d*/", i))
+			field.Set(reflect.ValueOf(saved[i]))
+		}
+	}
+	return printed
+}
+
+// attachInnerComment attaches a comment inside statement to the outermost
+// node that ends right before it on the same line, with only closing
+// brackets and commas in between, or else to the outermost node that
+// starts after it, preferring nodes that print their comments. Comments
+// after the last token of the statement trail the statement.
+func attachInnerComment(input string, tokens []Token, carriers []carrier, statement Expr, end Pos, comment Comment) {
+	next := sort.Search(len(tokens), func(i int) bool { return tokens[i].Pos >= comment.CommentEnd })
+	if next == len(tokens) || tokens[next].Pos >= end || tokens[next].Kind == ";" {
+		addTrailing(statement, comment)
+		return
+	}
+	var trailing, leading *carrier
+	for i := range carriers {
+		c := &carriers[i]
+		switch {
+		case c.span.end <= int(comment.CommentPos):
+			if sameLine(input, Pos(c.span.end), comment.CommentPos) &&
+				onlyClosingTokens(tokens, c.span.end, int(comment.CommentPos)) &&
+				(trailing == nil || c.span.end > trailing.span.end ||
+					c.span.end == trailing.span.end && (c.printed && !trailing.printed ||
+						c.printed == trailing.printed && c.span.start < trailing.span.start)) {
+				trailing = c
+			}
+		case c.span.start >= int(comment.CommentEnd):
+			if leading == nil || c.span.start < leading.span.start ||
+				c.span.start == leading.span.start && (c.printed && !leading.printed ||
+					c.printed == leading.printed && c.span.end > leading.span.end) {
+				leading = c
+			}
+		}
+	}
+	switch {
+	case trailing != nil:
+		addTrailing(trailing.target, comment)
+	case leading != nil:
+		addLeading(leading.target, comment)
+	default:
+		addTrailing(statement, comment)
+	}
+}
+
+func sameLine(input string, from, to Pos) bool {
+	return !strings.ContainsAny(input[from:to], "\r\n")
+}
+
+// onlyClosingTokens reports whether the tokens between start and end are
+// all commas or closing brackets.
+func onlyClosingTokens(tokens []Token, start, end int) bool {
+	i := sort.Search(len(tokens), func(i int) bool { return int(tokens[i].Pos) >= start })
+	for ; i < len(tokens) && int(tokens[i].Pos) < end; i++ {
+		switch tokens[i].Kind {
+		case ",", ")", "]", "}":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func addLeading(node Expr, comment Comment) {
+	if trivia := triviaOf(node); trivia != nil {
+		trivia.Leading = append(trivia.Leading, comment)
+	}
+}
+
+func addTrailing(node Expr, comment Comment) {
+	if trivia := triviaOf(node); trivia != nil {
+		trivia.Trailing = append(trivia.Trailing, comment)
+	}
+}
+
+// triviaField returns the Trivia field of node, or the zero Value if
+// comments can't be attached to node.
+func triviaField(node Expr) reflect.Value {
+	value := reflect.ValueOf(node)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	field := value.Elem().FieldByName("Trivia")
+	if !field.IsValid() || field.Type() != triviaType {
+		return reflect.Value{}
+	}
+	return field
+}
+
+// attachedTrivia returns the Trivia of node, or nil if node has no
+// comments attached.
+func attachedTrivia(node Expr) *Trivia {
+	field := triviaField(node)
+	if !field.IsValid() {
+		return nil
+	}
+	return field.Interface().(*Trivia)
+}
+
+// triviaOf returns the Trivia of node, allocating it if necessary,
+// or nil if comments can't be attached to node.
+func triviaOf(node Expr) *Trivia {
+	field := triviaField(node)
+	if !field.IsValid() {
+		return nil
+	}
+	if field.IsNil() {
+		field.Set(reflect.ValueOf(&Trivia{}))
+	}
+	return field.Interface().(*Trivia)
+}
+
+// withTrivia returns a shallow copy of node with its Trivia replaced.
+func withTrivia(node Expr, trivia *Trivia) Expr {
+	field := triviaField(node)
+	if !field.IsValid() || field.Interface().(*Trivia) == trivia {
+		return node
+	}
+	value := reflect.ValueOf(node).Elem()
+	copied := reflect.New(value.Type())
+	copied.Elem().Set(value)
+	copied.Elem().FieldByName("Trivia").Set(reflect.ValueOf(trivia))
+	return copied.Interface().(Expr)
+}
@@ -0,0 +1,230 @@
+package parser
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func commentTexts(comments []Comment) []string {
+	var texts []string
+	for _, comment := range comments {
+		texts = append(texts, comment.Text)
+	}
+	return texts
+}
+
+func TestAttachComments(t *testing.T) {
+	sql := `-- first
+SELECT 1; -- one
+/* second */
+SELECT 2 /* inner */ FROM t; /* two */ -- two again
+CREATE TABLE t1 (
+    -- id column
+    id UInt64, -- identifier
+    name String -- name
+) ENGINE = Memory;
+-- the end`
+	stmts, err := NewParser(sql).ParseStatements()
+	require.NoError(t, err)
+	require.Len(t, stmts, 3)
+
+	first := stmts[0].(*SelectQuery).Trivia
+	require.Equal(t, []string{"-- first"}, commentTexts(first.Leading))
+	require.Equal(t, []string{"-- one"}, commentTexts(first.Trailing))
+
+	second := stmts[1].(*SelectQuery)
+	require.Equal(t, []string{"/* second */"}, commentTexts(second.Trivia.Leading))
+	require.Equal(t, []string{"/* two */", "-- two again"}, commentTexts(second.Trivia.Trailing))
+	inner := second.SelectColumns.Items[0].(*NumberLiteral).Trivia
+	require.Equal(t, []string{"/* inner */"}, commentTexts(inner.Trailing))
+	require.Equal(t, Pos(strings.Index(sql, "/* inner */")), inner.Trailing[0].CommentPos)
+	require.Equal(t, Pos(strings.Index(sql, " FROM t;")), inner.Trailing[0].CommentEnd)
+
+	create := stmts[2].(*CreateTable)
+	require.Nil(t, create.Trivia.Leading)
+	require.Equal(t, []string{"-- the end"}, commentTexts(create.Trivia.Trailing))
+	id := create.TableSchema.Columns[0].(*Column)
+	require.Equal(t, []string{"-- id column"}, commentTexts(id.Trivia.Leading))
+	require.Equal(t, []string{"-- identifier"}, commentTexts(id.Trivia.Trailing))
+	name := create.TableSchema.Columns[1].(*Column)
+	require.Nil(t, name.Trivia.Leading)
+	require.Equal(t, []string{"-- name"}, commentTexts(name.Trivia.Trailing))
+
+	require.Equal(t, `CREATE TABLE t1
+(
+  -- id column
+  id UInt64, -- identifier
+  name String -- name
+)
+ENGINE = Memory -- the end
+`, create.String(0))
+}
+
+func TestAttachComments_InnerNodes(t *testing.T) {
+	stmts, err := NewParser(`SELECT a, -- c1
+    b
+FROM t1
+    -- joined
+    JOIN t2 ON t1.id = t2.id -- same id
+WHERE x = 1 -- one
+    -- two
+    AND y = 2`).ParseStatements()
+	require.NoError(t, err)
+	query := stmts[0].(*SelectQuery)
+	require.Nil(t, query.Trivia)
+	require.Equal(t, []string{"-- c1"}, commentTexts(query.SelectColumns.Items[0].(*Ident).Trivia.Trailing))
+	join := query.From.Expr.(*JoinExpr)
+	require.Equal(t, []string{"-- joined"}, commentTexts(join.Right.(*TableExpr).Trivia.Leading))
+	require.Equal(t, []string{"-- same id"}, commentTexts(query.From.Trivia.Trailing))
+	where := query.Where.Expr.(*BinaryExpr)
+	require.Equal(t, []string{"-- one"}, commentTexts(where.LeftExpr.(*BinaryExpr).Trivia.Trailing))
+	require.Equal(t, []string{"-- two"}, commentTexts(where.RightExpr.(*BinaryExpr).Trivia.Leading))
+
+	require.Equal(t, `
+SELECT 
+  a, -- c1
+  b
+FROM
+  t1
+  -- joined
+  JOIN t2 ON t1.id = t2.id -- same id
+WHERE
+  x = 1 -- one
+-- two
+AND y = 2`, query.String(0))
+}
+
+func TestAttachComments_WithRecovery(t *testing.T) {
+	stmts, errs := NewParser("-- broken\nCREATE TABLE; -- skipped\nSELECT 1 -- last").ParseStatementsWithRecovery()
+	require.Len(t, errs, 1)
+	require.Len(t, stmts, 2)
+	bad := stmts[0].(*BadStatement)
+	require.Equal(t, []string{"-- broken"}, commentTexts(bad.Trivia.Leading))
+	require.Equal(t, []string{"-- skipped"}, commentTexts(bad.Trivia.Trailing))
+	require.Equal(t, "-- broken\nCREATE TABLE -- skipped\n", bad.String(0))
+	require.Equal(t, []string{"-- last"}, commentTexts(stmts[1].(*SelectQuery).Trivia.Trailing))
+}
+
+func TestFormat_KeepsComments(t *testing.T) {
+	err := filepath.WalkDir("./testdata", func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(file) != ".sql" {
+			return err
+		}
+		t.Run(file, func(t *testing.T) {
+			fileBytes, err := os.ReadFile(file)
+			require.NoError(t, err)
+			sql := string(fileBytes)
+			if _, err := NewParser(sql).ParseStatements(); err != nil && strings.Contains(file, "/format/") {
+				t.Skip("some formatted outputs can't be parsed back")
+			}
+			requireCommentsKept(t, sql)
+			requireCommentsKept(t, withComments(t, sql, false))
+			requireCommentsKept(t, withComments(t, sql, true))
+		})
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestFormat_KeepsCommentsOfUnprintedNodes(t *testing.T) {
+	for _, sql := range []string{
+		"SET /* a */ x = 1 /* b */, y = 2",
+		"INSERT INTO t (a, b) -- c\n VALUES (1)",
+		"INSERT INTO t (/* c */ a) VALUES (1)",
+		"SELECT TOP /* a */ 10 /* b */ c FROM t",
+	} {
+		t.Run(sql, func(t *testing.T) {
+			requireCommentsKept(t, sql)
+		})
+	}
+	stmts, err := NewParser("INSERT INTO t (a, b) -- c\n VALUES (1)").ParseStatements()
+	require.NoError(t, err)
+	require.Equal(t, []string{"-- c"}, commentTexts(stmts[0].(*InsertExpr).Trivia.Trailing))
+}
+
+// requireCommentsKept checks that the statements in sql print all the
+// comments in it.
+func requireCommentsKept(t *testing.T, sql string) {
+	t.Helper()
+	stmts, err := NewParser(sql).ParseStatements()
+	require.NoError(t, err)
+	var builder strings.Builder
+	for _, stmt := range stmts {
+		builder.WriteString(stmt.String(0))
+		builder.WriteString(";\n")
+	}
+	printed := make(map[string]int)
+	for _, comment := range commentTokens(t, builder.String()) {
+		printed[comment]++
+	}
+	for _, comment := range commentTokens(t, sql) {
+		require.Positive(t, printed[comment], "%s is lost in\n%s", comment, builder.String())
+		printed[comment]--
+	}
+}
+
+// withComments returns sql with a comment before each token that follows
+// whitespace, or with a line comment at the end of each line.
+func withComments(t *testing.T, sql string, lineComments bool) string {
+	t.Helper()
+	tokens, err := Tokenize(sql)
+	require.NoError(t, err)
+	var builder strings.Builder
+	for i, token := range tokens {
+		switch {
+		case lineComments && token.Kind == TokenWhitespace && strings.Contains(token.Raw, "\n"):
+			fmt.Fprintf(&builder, " -- c%d", i)
+		case !lineComments && token.Kind != TokenWhitespace && (i == 0 || tokens[i-1].Kind == TokenWhitespace):
+			fmt.Fprintf(&builder, "/* c%d */ ", i)
+		}
+		builder.WriteString(token.Raw)
+	}
+	return builder.String()
+}
+
+// commentTokens returns the text of the comments in sql.
+func commentTokens(t *testing.T, sql string) []string {
+	t.Helper()
+	tokens, err := Tokenize(sql)
+	require.NoError(t, err)
+	var comments []string
+	for _, token := range tokens {
+		if token.Kind == TokenComment {
+			comments = append(comments, token.String)
+		}
+	}
+	return comments
+}
+
+func TestFormat_CommentsRoundTrip(t *testing.T) {
+	for _, sql := range []string{
+		"-- a\nSELECT a, -- the a column\nb FROM t1; -- done",
+		"/* header */ CREATE TABLE t1 (\n-- id\nid UInt64, -- key\nv String /* value */\n) ENGINE = Memory; -- end",
+		"USE db; -- switch\nSET max_threads = 1 /* one */;",
+		"SELECT a, -- c1\n b FROM t",
+		"SELECT f(x, -- x\n/* y */ y) AS v FROM t1\n-- join\nJOIN t2 ON t1.id = t2.id -- id\nWHERE a = 1 -- one\n-- two\nOR b = 2",
+		"SELECT a FROM t WHERE b IN (1, -- one\n2) -- in\nGROUP BY a -- by a",
+	} {
+		t.Run(sql, func(t *testing.T) {
+			stmts, err := NewParser(sql).ParseStatements()
+			require.NoError(t, err)
+			var builder strings.Builder
+			for _, stmt := range stmts {
+				builder.WriteString(stmt.String(0))
+				builder.WriteString(";\n")
+			}
+			reparsed, err := NewParser(builder.String()).ParseStatements()
+			require.NoError(t, err)
+			require.Len(t, reparsed, len(stmts))
+			for i := range stmts {
+				require.True(t, Equal(stmts[i], reparsed[i], EqualOptions{IgnorePos: true}))
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TextEdit replaces the source text from Start to End with NewText.
+// An edit with Start == End inserts NewText.
+type TextEdit struct {
+	Start   Pos
+	End     Pos
+	NewText string
+}
+
+// NodeRange returns the range of node in source, the SQL it was parsed
+// from. Unlike Pos and End, the range includes the quotes or heredoc
+// delimiters of a string or quoted identifier at either end of the node.
+func NodeRange(source string, node Expr) (start, end Pos) {
+	start, end = node.Pos(), node.End()
+	if start > 0 && int(start) <= len(source) {
+		opening := int(start) - 1
+		if source[opening] == '$' {
+			opening = strings.LastIndexByte(source[:opening], '$')
+		}
+		if opening >= 0 && (isQuote(source[opening]) || source[opening] == '$') {
+			token, err := NewLexer(source[opening:]).peekToken()
+			if err == nil && token != nil && tokenStart(token) == 0 && Pos(opening)+token.Pos == start {
+				start = Pos(opening)
+			}
+		}
+	}
+	if int(end) < len(source) && end > start {
+		if isQuote(source[end]) {
+			end++
+		} else if n := heredocDelimiter(source[end:]); n > 0 {
+			end += Pos(n)
+		}
+	}
+	return start, end
+}
+
+func isQuote(c byte) bool {
+	return c == '\'' || c == '"' || c == '`'
+}
+
+// ReplaceNode returns the edit replacing node, which was parsed from
+// source, with replacement.
+func ReplaceNode(source string, node, replacement Expr) TextEdit {
+	start, end := NodeRange(source, node)
+	return TextEdit{Start: start, End: end, NewText: render(replacement)}
+}
+
+// InsertBefore returns the edit inserting inserted, followed by a space,
+// in front of node, e.g. a column before another column.
+func InsertBefore(source string, node, inserted Expr) TextEdit {
+	start, _ := NodeRange(source, node)
+	return TextEdit{Start: start, End: start, NewText: render(inserted) + " "}
+}
+
+// InsertAfter returns the edit inserting a space and inserted after node,
+// e.g. a WHERE clause after the FROM clause.
+func InsertAfter(source string, node, inserted Expr) TextEdit {
+	_, end := NodeRange(source, node)
+	return TextEdit{Start: end, End: end, NewText: " " + render(inserted)}
+}
+
+// DeleteNode returns the edit removing node from source.
+func DeleteNode(source string, node Expr) TextEdit {
+	start, end := NodeRange(source, node)
+	return TextEdit{Start: start, End: end}
+}
+
+// ApplyEdits applies the edits to source. The edits may be given in any
+// order, but must not overlap; insertions at the same position are applied
+// in the given order.
+func ApplyEdits(source string, edits []TextEdit) (string, error) {
+	sorted := make([]TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Start != sorted[j].Start {
+			return sorted[i].Start < sorted[j].Start
+		}
+		return sorted[i].End < sorted[j].End
+	})
+
+	result := make([]byte, 0, len(source))
+	offset := Pos(0)
+	for _, edit := range sorted {
+		if edit.Start > edit.End || int(edit.End) > len(source) || edit.Start < 0 {
+			return "", fmt.Errorf("edit range %d-%d is out of bounds", edit.Start, edit.End)
+		}
+		if edit.Start < offset {
+			return "", fmt.Errorf("edit range %d-%d overlaps with another edit", edit.Start, edit.End)
+		}
+		result = append(result, source[offset:edit.Start]...)
+		result = append(result, edit.NewText...)
+		offset = edit.End
+	}
+	result = append(result, source[offset:]...)
+	return string(result), nil
+}
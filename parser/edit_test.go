@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextEdits(t *testing.T) {
+	source := "SELECT a, `b c`, 'x' FROM db.t1  -- keep\nLIMIT 10"
+	query := parseSingleStatement(t, source).(*SelectQuery)
+	items := query.SelectColumns.Items
+
+	start, end := NodeRange(source, items[1])
+	require.Equal(t, "`b c`", source[start:end])
+	start, end = NodeRange(source, items[2])
+	require.Equal(t, "'x'", source[start:end])
+
+	var table *TableIdentifier
+	Inspect(query.From, func(node Expr) bool {
+		if t, ok := node.(*TableIdentifier); ok {
+			table = t
+		}
+		return true
+	})
+	where := &WhereExpr{Expr: &BinaryExpr{
+		LeftExpr:  &Ident{Name: "a"},
+		Operation: ">",
+		RightExpr: &NumberLiteral{Literal: "1"},
+	}}
+
+	edits := []TextEdit{
+		InsertAfter(source, table, where),
+		ReplaceNode(source, items[2], &StringLiteral{Literal: "y"}),
+		ReplaceNode(source, table, &TableIdentifier{Table: &Ident{Name: "t2"}}),
+		InsertBefore(source, query.Limit, &OrderByListExpr{Items: []Expr{&OrderByExpr{Expr: &Ident{Name: "a"}, Direction: OrderDirectionNone}}}),
+	}
+	result, err := ApplyEdits(source, edits)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a, `b c`, 'y' FROM t2 WHERE\n  a > 1  -- keep\nORDER BY a LIMIT 10", result)
+
+	result, err = ApplyEdits(source, []TextEdit{DeleteNode(source, query.Limit)})
+	require.NoError(t, err)
+	require.Equal(t, "SELECT a, `b c`, 'x' FROM db.t1  -- keep\n", result)
+}
+
+func TestNodeRange(t *testing.T) {
+	expected := []string{
+		"arr[1]", "tup.1", "{p:UInt8}", "(1, 'x')", "{'a': 1}",
+		"x.:Int64", "DATE '2020-01-01'", "a BETWEEN 1 AND 2",
+	}
+	source := "SELECT " + strings.Join(expected, ", ")
+	query := parseSingleStatement(t, source).(*SelectQuery)
+	items := query.SelectColumns.Items
+	require.Len(t, items, len(expected))
+	for i, item := range items {
+		start, end := NodeRange(source, item)
+		require.Equal(t, expected[i], source[start:end])
+
+		reparsed, err := ParseExpr(source[start:end])
+		require.NoError(t, err)
+		require.True(t, Equal(item, reparsed, EqualOptions{IgnorePos: true}), expected[i])
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		edits    []TextEdit
+		expected string
+		err      string
+	}{
+		{
+			name:     "no edits",
+			expected: "abcdef",
+		},
+		{
+			name:     "inserts at the same position keep their order",
+			edits:    []TextEdit{{Start: 3, End: 3, NewText: "1"}, {Start: 3, End: 3, NewText: "2"}},
+			expected: "abc12def",
+		},
+		{
+			name:     "insert before a replacement",
+			edits:    []TextEdit{{Start: 2, End: 4, NewText: "X"}, {Start: 2, End: 2, NewText: "-"}},
+			expected: "ab-Xef",
+		},
+		{
+			name:     "adjacent replacements",
+			edits:    []TextEdit{{Start: 3, End: 6, NewText: "2"}, {Start: 0, End: 3, NewText: "1"}},
+			expected: "12",
+		},
+		{
+			name:  "overlap",
+			edits: []TextEdit{{Start: 0, End: 3}, {Start: 2, End: 4}},
+			err:   "edit range 2-4 overlaps with another edit",
+		},
+		{
+			name:  "out of bounds",
+			edits: []TextEdit{{Start: 4, End: 7}},
+			err:   "edit range 4-7 is out of bounds",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ApplyEdits("abcdef", tc.edits)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError is returned by the Parse* methods when the input is not
+// valid ClickHouse SQL.
+type ParseError struct {
+	Pos Pos
+	// Line and Column are 1-based. Column counts characters, not bytes.
+	Line   int
+	Column int
+	// Token is the offending token, or nil at the end of input.
+	Token *Token
+	// Expected lists the keywords and token kinds that would have been
+	// accepted at Pos, if known.
+	Expected []string
+	Message  string
+
+	sourceLine string
+}
+
+func (e *ParseError) Error() string {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("line %d:%d %s\n", e.Line, e.Column, e.Message))
+	buf.WriteString(e.sourceLine)
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Repeat(" ", e.Column-1))
+	width := 1
+	if e.Token != nil && e.Token.String != "" {
+		width = utf8.RuneCountInString(e.Token.String)
+	}
+	buf.WriteString(strings.Repeat("^", width))
+	buf.WriteByte('\n')
+	return buf.String()
+}
+
+// expectedError is returned when the current token is none of the
+// keywords or token kinds the parser accepts at this point.
+type expectedError struct {
+	expected []string
+	got      string
+}
+
+func (e *expectedError) Error() string {
+	return fmt.Sprintf("expected %s, but got %s", strings.Join(e.expected, "|"), e.got)
+}
+
+func (p *Parser) errExpected(expected ...string) error {
+	got := string(TokenEOF)
+	if last := p.last(); last != nil {
+		got = strconv.Quote(last.String)
+	}
+	return &expectedError{expected: expected, got: got}
+}
+
+func (p *Parser) wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var wrapped *ParseError
+	if errors.As(err, &wrapped) {
+		return wrapped
+	}
+
+	parseErr := &ParseError{
+		Pos:     p.Pos(),
+		Line:    1,
+		Column:  1,
+		Message: err.Error(),
+	}
+	var positioned *positionedError
+	if errors.As(err, &positioned) {
+		parseErr.Pos = positioned.pos
+	} else if last := p.last(); last != nil {
+		token := *last
+		parseErr.Token = &token
+	}
+	var expected *expectedError
+	if errors.As(err, &expected) {
+		parseErr.Expected = expected.expected
+	}
+
+	input := p.lexer.input
+	offset := int(parseErr.Pos)
+	if offset > len(input) {
+		offset = len(input)
+	}
+	lineStart := strings.LastIndexByte(input[:offset], '\n') + 1
+	parseErr.Line += strings.Count(input[:lineStart], "\n")
+	parseErr.Column += utf8.RuneCountInString(input[lineStart:offset])
+	lineEnd := strings.IndexByte(input[lineStart:], '\n')
+	if lineEnd < 0 {
+		parseErr.sourceLine = input[lineStart:]
+	} else {
+		parseErr.sourceLine = input[lineStart : lineStart+lineEnd]
+	}
+	return parseErr
+}
@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseError(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		sql      string
+		line     int
+		column   int
+		token    string
+		expected []string
+		message  string
+	}{
+		{
+			name:     "missing keyword",
+			sql:      "SELECT * FROM t GROUP a",
+			line:     1,
+			column:   23,
+			token:    "a",
+			expected: []string{KeywordBy},
+			message:  `expected BY, but got "a"`,
+		},
+		{
+			name:    "invalid hexadecimal escape",
+			sql:     `SELECT 'a\x4'`,
+			line:    1,
+			column:  10,
+			message: "invalid hexadecimal escape sequence",
+		},
+		{
+			name:    "invalid hexadecimal escape in a quoted identifier",
+			sql:     "SELECT a FROM t\nWHERE `b\\xZZ` = 1",
+			line:    2,
+			column:  9,
+			message: "invalid hexadecimal escape sequence",
+		},
+		{
+			name:     "multi-byte characters before the error",
+			sql:      "SELECT 'héllo', x FROM t\nWHERE a = 'ü' GROUP a",
+			line:     2,
+			column:   21,
+			token:    "a",
+			expected: []string{KeywordBy},
+			message:  `expected BY, but got "a"`,
+		},
+		{
+			name:     "one of several keywords",
+			sql:      "CREATE ROLE r ON ON CLUSTER c",
+			line:     1,
+			column:   18,
+			token:    "ON",
+			expected: []string{KeywordCluster},
+			message:  `expected CLUSTER, but got "ON"`,
+		},
+		{
+			name:   "unexpected end of input",
+			sql:    "SELECT a FROM t WHERE",
+			line:   1,
+			column: 22,
+			expected: []string{
+				KeywordInterval, KeywordDate, KeywordTimestamp, KeywordCast, KeywordCase, KeywordExtract,
+				"<int>", "<float>", "<ident>", "<string>", "{", "(", "*", "[",
+			},
+			message: "expected INTERVAL|DATE|TIMESTAMP|CAST|CASE|EXTRACT|<int>|<float>|<ident>|<string>|{|(|*|[, but got <eof>",
+		},
+		{
+			name:     "missing statement terminator",
+			sql:      "SELECT a FROM t)",
+			line:     1,
+			column:   16,
+			token:    ")",
+			expected: []string{"<eof>", ";"},
+			message:  `expected <eof>|;, but got ")"`,
+		},
+		{
+			name:   "unknown statement",
+			sql:    "UPSERT INTO t VALUES (1)",
+			line:   1,
+			column: 1,
+			token:  "UPSERT",
+			expected: []string{
+				KeywordCreate, KeywordAttach, KeywordAlter, KeywordDrop, KeywordDetach, KeywordTruncate,
+				KeywordRename, KeywordSelect, KeywordWith, KeywordDelete, KeywordInsert, KeywordUse, KeywordSet,
+				KeywordSystem, KeywordOptimize, KeywordCheck, KeywordExplain, KeywordGrant,
+			},
+			message: `expected CREATE|ATTACH|ALTER|DROP|DETACH|TRUNCATE|RENAME|SELECT|WITH|DELETE|INSERT|USE|SET|` +
+				`SYSTEM|OPTIMIZE|CHECK|EXPLAIN|GRANT, but got "UPSERT"`,
+		},
+		{
+			name:     "missing table",
+			sql:      "SELECT a FROM 1",
+			line:     1,
+			column:   15,
+			token:    "1",
+			expected: []string{"<ident>", "("},
+			message:  `expected <ident>|(, but got "1"`,
+		},
+		{
+			name:     "missing engine name",
+			sql:      "CREATE TABLE t (a Int32) ENGINE = 'x'",
+			line:     1,
+			column:   36,
+			token:    "x",
+			expected: []string{KeywordNull, "<ident>"},
+			message:  `expected NULL|<ident>, but got "x"`,
+		},
+		{
+			name:     "invalid type parameter",
+			sql:      "CREATE TABLE t (a Decimal(-)) ENGINE = Memory",
+			line:     1,
+			column:   27,
+			token:    "-",
+			expected: []string{"<ident>", "<string>", "<int>"},
+			message:  `expected <ident>|<string>|<int>, but got "-"`,
+		},
+		{
+			name:    "invalid digit separator",
+			sql:     "SELECT 1_",
+			line:    1,
+			column:  8,
+			message: "invalid number",
+		},
+		{
+			name:    "invalid binary digit",
+			sql:     "SELECT a FROM t WHERE b = 0b102",
+			line:    1,
+			column:  27,
+			message: "invalid number",
+		},
+		{
+			name:    "hexadecimal prefix without digits",
+			sql:     "SELECT 0x",
+			line:    1,
+			column:  8,
+			message: "invalid number",
+		},
+		{
+			name:    "hexadecimal float without exponent digits",
+			sql:     "SELECT 0x1.8p + 1",
+			line:    1,
+			column:  8,
+			message: "exponent part should contain at least one digit",
+		},
+		{
+			name:    "unterminated comment hiding statements",
+			sql:     "SELECT 1;\n/* TODO: drop it\nDROP TABLE t;\nSELECT 2;",
+			line:    2,
+			column:  1,
+			message: "unterminated comment",
+		},
+		{
+			name:    "unterminated string",
+			sql:     "SELECT a FROM t WHERE b = 'x;\nSELECT 2",
+			line:    1,
+			column:  27,
+			message: "unterminated string",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewParser(tc.sql).ParseStatements()
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr))
+			require.Equal(t, tc.line, parseErr.Line)
+			require.Equal(t, tc.column, parseErr.Column)
+			require.Equal(t, tc.expected, parseErr.Expected)
+			require.Equal(t, tc.message, parseErr.Message)
+			if tc.token == "" {
+				require.Nil(t, parseErr.Token)
+			} else {
+				require.Equal(t, tc.token, parseErr.Token.String)
+				require.Equal(t, parseErr.Pos, parseErr.Token.Pos)
+			}
+		})
+	}
+}
+
+func TestParseError_Error(t *testing.T) {
+	_, err := NewParser("SELECT 1;\nALTER TABLE t FOO").ParseStatements()
+	require.EqualError(t, err, "line 2:15 expected ADD|DROP, but got \"FOO\"\n"+
+		"ALTER TABLE t FOO\n"+
+		"              ^^^\n")
+}
+
+func TestParseError_WrappedErrors(t *testing.T) {
+	p := NewParser("SELECT 1,\n  2")
+	err := p.wrapError(fmt.Errorf("in select list: %w", &positionedError{pos: 12, msg: "bad item"}))
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, "in select list: bad item", parseErr.Message)
+	require.Equal(t, 2, parseErr.Line)
+	require.Equal(t, 3, parseErr.Column)
+
+	err = p.wrapError(fmt.Errorf("in select list: %w", p.errExpected(KeywordFrom)))
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, []string{KeywordFrom}, parseErr.Expected)
+
+	inner := p.wrapError(&positionedError{pos: 12, msg: "bad item"})
+	require.Same(t, inner, p.wrapError(fmt.Errorf("again: %w", inner)))
+}
@@ -0,0 +1,85 @@
+package parser
+
+import "strings"
+
+// unescapeSequence decodes the escape sequence at the start of s, which
+// begins with a backslash, and returns the decoded bytes and the length of
+// the sequence, or a length of 0 if the sequence is an invalid hexadecimal
+// escape. As in ClickHouse, a backslash followed by a character without
+// special meaning stands for that character. s must hold at least two bytes.
+func unescapeSequence(s string) (string, int) {
+	switch s[1] {
+	case 'b':
+		return "\b", 2
+	case 'f':
+		return "\f", 2
+	case 'r':
+		return "\r", 2
+	case 'n':
+		return "\n", 2
+	case 't':
+		return "\t", 2
+	case '0':
+		return "\x00", 2
+	case 'a':
+		return "\a", 2
+	case 'v':
+		return "\v", 2
+	case 'x', 'X':
+		if len(s) < 4 || !IsHexDigit(s[2]) || !IsHexDigit(s[3]) {
+			return "", 0
+		}
+		return string([]byte{hexValue(s[2])<<4 | hexValue(s[3])}), 4
+	default:
+		return s[1:2], 2
+	}
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case IsDigit(c):
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// quoteString returns s enclosed in quote, escaping the quote, backslashes
+// and control characters so that the lexer decodes it back to s.
+func quoteString(s string, quote byte) string {
+	const hexDigits = "0123456789ABCDEF"
+	var builder strings.Builder
+	builder.Grow(len(s) + 2)
+	builder.WriteByte(quote)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case quote, '\\':
+			builder.WriteByte('\\')
+			builder.WriteByte(c)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case 0:
+			builder.WriteString(`\0`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				builder.WriteString(`\x`)
+				builder.WriteByte(hexDigits[c>>4])
+				builder.WriteByte(hexDigits[c&0xf])
+			} else {
+				builder.WriteByte(c)
+			}
+		}
+	}
+	builder.WriteByte(quote)
+	return builder.String()
+}
@@ -0,0 +1,273 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// KindKey is the name of the JSON property holding the node type name,
+// e.g. "SelectQuery" or "BinaryExpr", in the output of MarshalExpr.
+const KindKey = "kind"
+
+// nodeKinds maps the kind of every AST node type to its struct type.
+var nodeKinds = func() map[string]reflect.Type {
+	kinds := make(map[string]reflect.Type)
+	for _, node := range []Expr{
+		&OperationExpr{}, &Ident{}, &NullLiteral{}, &NumberLiteral{}, &StringLiteral{},
+		&AlterTableRemoveTTL{}, &WindowFrameCurrentRow{}, &WindowFrameUnbounded{}, &SystemDropExpr{},
+		&TernaryExpr{}, &BinaryExpr{}, &UnaryExpr{}, &NotExpr{}, &NegateExpr{}, &GlobalInExpr{},
+		&IsNullExpr{}, &IsNotNullExpr{}, &AliasExpr{}, &NotNullLiteral{}, &NestedIdentifier{},
+		&ColumnIdentifier{}, &TableIdentifier{}, &UUID{}, &RatioExpr{}, &EnumValueExpr{},
+		&EnumValueExprList{}, &IntervalExpr{}, &ExtractExpr{}, &CastExpr{}, &CaseExpr{}, &WhenExpr{},
+		&FunctionExpr{}, &WindowFunctionExpr{}, &ParamExprList{}, &ArrayParamList{},
+		&ColumnExprList{}, &Column{}, &ScalarTypeExpr{}, &PropertyTypeExpr{},
+		&ColumnTypeExpr{}, &TypeWithParamsExpr{}, &ComplexTypeExpr{}, &NestedTypeExpr{},
+		&CompressionCodec{}, &DefaultExpr{}, &ConstraintExpr{}, &TableIndex{}, &RemovePropertyType{},
+		&TableSchemaExpr{}, &TableArgListExpr{}, &TableFunctionExpr{}, &OnClusterExpr{}, &PartitionExpr{},
+		&PartitionByExpr{}, &PrimaryKeyExpr{}, &SampleByExpr{}, &TTLExpr{}, &TTLExprList{},
+		&OrderByExpr{}, &OrderByListExpr{}, &SettingsExpr{}, &SettingsExprList{}, &EngineExpr{},
+		&DestinationExpr{}, &SubQueryExpr{}, &WithTimeoutExpr{}, &SelectQuery{}, &WithExpr{}, &CTEExpr{},
+		&TopExpr{}, &FromExpr{}, &TableExpr{}, &JoinExpr{}, &JoinConstraintExpr{}, &OnExpr{},
+		&UsingExpr{}, &SampleRatioExpr{}, &ArrayJoinExpr{}, &WindowExpr{}, &WindowConditionExpr{},
+		&WindowFrameExpr{}, &WindowFrameExtendExpr{}, &WindowFrameRangeExpr{}, &WindowFrameNumber{},
+		&PrewhereExpr{}, &WhereExpr{}, &GroupByExpr{}, &HavingExpr{}, &LimitExpr{}, &LimitByExpr{},
+		&CreateDatabase{}, &CreateTable{}, &CreateMaterializedView{}, &CreateView{}, &CreateLiveView{},
+		&CreateFunction{}, &CreateRole{}, &AlterRole{}, &RoleName{}, &RoleRenamePair{}, &RoleSetting{},
+		&SettingPair{}, &DropDatabase{}, &DropStmt{}, &DropUserOrRole{}, &TruncateTable{}, &RenameStmt{},
+		&TargetPair{}, &AlterTable{}, &AlterTableAttachPartition{}, &AlterTableDetachPartition{},
+		&AlterTableDropPartition{}, &AlterTableFreezePartition{}, &AlterTableAddColumn{},
+		&AlterTableAddIndex{}, &AlterTableDropColumn{}, &AlterTableDropIndex{}, &AlterTableClearColumn{},
+		&AlterTableClearIndex{}, &AlterTableRenameColumn{}, &AlterTableModifyTTL{},
+		&AlterTableModifyColumn{}, &AlterTableReplacePartition{}, &UseExpr{}, &SetExpr{}, &FormatExpr{},
+		&OptimizeExpr{}, &DeduplicateExpr{}, &SystemExpr{}, &SystemFlushExpr{}, &SystemReloadExpr{},
+		&SystemSyncExpr{}, &SystemCtrlExpr{}, &DeleteFromExpr{}, &InsertExpr{}, &ColumnNamesExpr{},
+		&ValuesExpr{}, &CheckExpr{}, &ExplainExpr{}, &GrantPrivilegeExpr{}, &PrivilegeExpr{},
+		&BadStatement{}, &QueryParam{}, &BetweenExpr{}, &LambdaExpr{},
+		&IndexExpr{}, &TupleAccessExpr{}, &JSONPathExpr{}, &TypedLiteral{}, &TupleLiteral{},
+		&MapLiteral{}, &MapEntry{},
+	} {
+		typ := reflect.TypeOf(node).Elem()
+		kinds[typ.Name()] = typ
+	}
+	return kinds
+}()
+
+// KindOf returns the kind of node as it is written to JSON by MarshalExpr.
+func KindOf(node Expr) string {
+	return reflect.Indirect(reflect.ValueOf(node)).Type().Name()
+}
+
+// MarshalExpr returns the JSON encoding of the tree rooted at node. Every
+// node is encoded as an object whose "kind" property holds the node type
+// name, followed by the struct fields in declaration order.
+func MarshalExpr(node Expr) ([]byte, error) {
+	var buf bytes.Buffer
+	if isNilExpr(node) {
+		buf.WriteString("null")
+	} else if err := encodeValue(&buf, reflect.ValueOf(node)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalStatements returns the JSON encoding of stmts as an array
+// of nodes encoded by MarshalExpr.
+func MarshalStatements(stmts []Expr) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, stmt := range stmts {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := MarshalExpr(stmt)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+func encodeValue(buf *bytes.Buffer, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return encodeValue(buf, v.Elem())
+	case reflect.Struct:
+		buf.WriteByte('{')
+		sep := ""
+		if _, ok := nodeKinds[v.Type().Name()]; ok {
+			fmt.Fprintf(buf, "%q:%q", KindKey, v.Type().Name())
+			sep = ","
+		}
+		for i := 0; i < v.NumField(); i++ {
+			fmt.Fprintf(buf, "%s%q:", sep, v.Type().Field(i).Name)
+			sep = ","
+			if err := encodeValue(buf, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeValue(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		return nil
+	}
+}
+
+// UnmarshalExpr rebuilds the tree encoded by MarshalExpr.
+func UnmarshalExpr(data []byte) (Expr, error) {
+	var node Expr
+	if err := decodeValue(data, reflect.ValueOf(&node).Elem()); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// UnmarshalStatement is like UnmarshalExpr, but returns an error if the
+// encoded node is not a statement that ParseStatements could return.
+func UnmarshalStatement(data []byte) (Expr, error) {
+	node, err := UnmarshalExpr(data)
+	if err != nil {
+		return nil, err
+	}
+	if !isStatement(node) {
+		return nil, fmt.Errorf("%s is not a statement", KindOf(node))
+	}
+	return node, nil
+}
+
+// UnmarshalStatements rebuilds the statements encoded by MarshalStatements.
+func UnmarshalStatements(data []byte) ([]Expr, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	stmts := make([]Expr, 0, len(items))
+	for i, item := range items {
+		stmt, err := UnmarshalStatement(item)
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i, err)
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+func isStatement(node Expr) bool {
+	switch node.(type) {
+	case DDL, *SelectQuery, *DeleteFromExpr, *InsertExpr, *UseExpr, *SetExpr,
+		*SystemExpr, *OptimizeExpr, *CheckExpr, *ExplainExpr, *BadStatement:
+		return true
+	default:
+		return false
+	}
+}
+
+func decodeValue(data []byte, v reflect.Value) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.Kind() == reflect.Ptr {
+			if _, ok := nodeKinds[v.Type().Elem().Name()]; !ok {
+				break
+			}
+		}
+		node, err := decodeNode(data)
+		if err != nil {
+			return err
+		}
+		value := reflect.ValueOf(node)
+		if !value.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("cannot use %s as %s", KindOf(node), v.Type())
+		}
+		v.Set(value)
+		return nil
+	case reflect.Struct:
+		if _, ok := nodeKinds[v.Type().Name()]; !ok {
+			break
+		}
+		node, err := decodeNode(data)
+		if err != nil {
+			return err
+		}
+		value := reflect.ValueOf(node)
+		if value.Type().Elem() != v.Type() {
+			return fmt.Errorf("cannot use %s as %s", KindOf(node), v.Type())
+		}
+		v.Set(value.Elem())
+		return nil
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return json.Unmarshal(data, v.Addr().Interface())
+}
+
+func decodeNode(data []byte) (Expr, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	var kind string
+	if err := json.Unmarshal(fields[KindKey], &kind); err != nil {
+		return nil, fmt.Errorf("missing or invalid %q property", KindKey)
+	}
+	typ, ok := nodeKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown node kind %q", kind)
+	}
+	node := reflect.New(typ)
+	for name, raw := range fields {
+		if name == KindKey {
+			continue
+		}
+		field := node.Elem().FieldByName(name)
+		if !field.IsValid() {
+			return nil, fmt.Errorf("unknown field %q for %s", name, kind)
+		}
+		if err := decodeValue(raw, field); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", kind, name, err)
+		}
+	}
+	return node.Interface().(Expr), nil
+}
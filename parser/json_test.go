@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalExpr_RoundTrip(t *testing.T) {
+	for name, stmts := range parseTestdataStatements(t) {
+		t.Run(name, func(t *testing.T) {
+			data, err := MarshalStatements(stmts)
+			require.NoError(t, err)
+			require.True(t, json.Valid(data))
+
+			decoded, err := UnmarshalStatements(data)
+			require.NoError(t, err)
+			require.Len(t, decoded, len(stmts))
+			for i := range stmts {
+				require.True(t, Equal(stmts[i], decoded[i], EqualOptions{}))
+				require.Equal(t, stmts[i].String(0), decoded[i].String(0))
+			}
+		})
+	}
+}
+
+func TestMarshalExpr_Kind(t *testing.T) {
+	stmt := parseSingleStatement(t, "SELECT a + 1 FROM t1 WHERE b IN (1, 2)")
+	data, err := MarshalExpr(stmt)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data, []byte(`{"kind":"SelectQuery",`)))
+
+	var tree interface{}
+	require.NoError(t, json.Unmarshal(data, &tree))
+	var kinds []string
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			kind, ok := v[KindKey].(string)
+			require.True(t, ok, "object without kind: %v", v)
+			kinds = append(kinds, kind)
+			for _, field := range v {
+				collect(field)
+			}
+		case []interface{}:
+			for _, item := range v {
+				collect(item)
+			}
+		}
+	}
+	collect(tree)
+	require.Contains(t, kinds, "BinaryExpr")
+	require.Contains(t, kinds, "WhereExpr")
+
+	data, err = MarshalExpr(nil)
+	require.NoError(t, err)
+	require.Equal(t, "null", string(data))
+}
+
+func TestUnmarshalExpr_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"missing kind", `{"Name":"a"}`, `missing or invalid "kind" property`},
+		{"unknown kind", `{"kind":"Foo"}`, `unknown node kind "Foo"`},
+		{"unknown field", `{"kind":"Ident","Foo":1}`, `unknown field "Foo" for Ident`},
+		{"type mismatch", `{"kind":"TableIdentifier","Table":{"kind":"NumberLiteral"}}`, "TableIdentifier.Table: cannot use NumberLiteral as *parser.Ident"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := UnmarshalExpr([]byte(tc.data))
+			require.EqualError(t, err, tc.err)
+		})
+	}
+
+	node, err := UnmarshalExpr([]byte(`{"kind":"Ident","Name":"a"}`))
+	require.NoError(t, err)
+	require.Equal(t, "a", node.String(0))
+	_, err = UnmarshalStatement([]byte(`{"kind":"Ident","Name":"a"}`))
+	require.EqualError(t, err, "Ident is not a statement")
+}
+
+func TestNodeKinds_Complete(t *testing.T) {
+	file, err := goparser.ParseFile(token.NewFileSet(), "ast.go", nil, 0)
+	require.NoError(t, err)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != "Pos" {
+			continue
+		}
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		name := recv.(*ast.Ident).Name
+		require.Containsf(t, nodeKinds, name, "%s is not registered in nodeKinds", name)
+	}
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 	"unicode"
@@ -9,14 +8,16 @@ import (
 )
 
 const (
-	TokenEOF     TokenKind = "<eof>"
-	TokenIdent   TokenKind = "<ident>"
-	TokenKeyword TokenKind = "<keyword>"
-	TokenInt     TokenKind = "<int>"
-	TokenFloat   TokenKind = "<float>"
-	TokenString  TokenKind = "<string>"
-	TokenCast    TokenKind = "<cast>"
-	TokenArrow   TokenKind = "<arrow>"
+	TokenEOF        TokenKind = "<eof>"
+	TokenIdent      TokenKind = "<ident>"
+	TokenKeyword    TokenKind = "<keyword>"
+	TokenInt        TokenKind = "<int>"
+	TokenFloat      TokenKind = "<float>"
+	TokenString     TokenKind = "<string>"
+	TokenCast       TokenKind = "<cast>"
+	TokenArrow      TokenKind = "<arrow>"
+	TokenComment    TokenKind = "<comment>"
+	TokenWhitespace TokenKind = "<whitespace>"
 )
 
 type Pos int
@@ -28,14 +29,60 @@ type Token struct {
 
 	Kind     TokenKind
 	String   string
-	Base     int // 10 or 16 on TokenInt
+	Base     int // 2, 10 or 16 on TokenInt and TokenFloat
 	Unquoted bool
+	// Raw is the source text of the token, including quotes. It is only
+	// set on the tokens returned by Tokenize.
+	Raw string
+	// Heredoc is the delimiter, e.g. $$ or $tag$, of a heredoc TokenString.
+	Heredoc string
 }
 
 type Lexer struct {
 	input     string
 	current   int
 	lastToken *Token
+	// comments are all the comments consumed so far, in input order.
+	comments []*Token
+	// err is the first lexical error, e.g. an invalid number or an
+	// unterminated string, quoted identifier or comment.
+	err error
+	// truncated is set by an unterminated string, quoted identifier or
+	// comment, which hides the rest of the input from the parser.
+	truncated bool
+}
+
+// positionedError is an error at a position other than the last token,
+// e.g. at the start of an unterminated comment.
+type positionedError struct {
+	pos Pos
+	msg string
+}
+
+func (e *positionedError) Error() string {
+	return e.msg
+}
+
+// unterminated records and returns the error for an unterminated string,
+// quoted identifier or comment starting at the current position.
+func (l *Lexer) unterminated(format string, args ...any) error {
+	l.truncated = true
+	return l.invalid(format, args...)
+}
+
+// invalid records and returns the error for an invalid token starting at
+// the current position.
+func (l *Lexer) invalid(format string, args ...any) error {
+	return l.invalidAt(Pos(l.current), format, args...)
+}
+
+// invalidAt is like invalid, but for an error at pos inside the token.
+func (l *Lexer) invalidAt(pos Pos, format string, args ...any) error {
+	err := &positionedError{pos: pos, msg: fmt.Sprintf(format, args...)}
+	if l.err == nil {
+		l.err = err
+	}
+	return err
 }
 
 func NewLexer(buf string) *Lexer {
@@ -69,46 +116,63 @@ func (l *Lexer) consumeNumber() error {
 		// skip sign
 		i++
 	}
-	if l.peekN(0) == '0' && l.peekOk(1) && l.peekN(1) == 'x' {
-		i += 2
-		base = 16
+	if n := numberWordLen(l.input[l.current+i:]); n > 0 {
+		return l.consumeNumberWord(i + n)
+	}
+	if l.peekN(i) == '0' && l.peekOk(i+1) {
+		switch l.peekN(i + 1) {
+		case 'x', 'X':
+			i += 2
+			base = 16
+		case 'b', 'B':
+			i += 2
+			base = 2
+		}
 	}
 
 	hasExp := false
 	tokenKind := TokenInt
-	hasNumberPart := false
+	hasDigit := false
 	for l.peekOk(i) {
-		hasNumberPart = true
 		c := l.peekN(i)
 		switch {
-		case base == 10 && IsDigit(c):
+		case !hasExp && isDigitOf(c, base):
+			hasDigit = true
 			i++
 			continue
-		case base == 16 && IsHexDigit(c):
+		case c == '_' && hasDigit && l.peekOk(i+1) && isDigitOf(l.peekN(i+1), base):
+			// digit separator, e.g. 1_000_000
 			i++
 			continue
-		case c == '.': // float
+		case c == '.' && base != 2: // float
 			tokenKind = TokenFloat
 			i++
 			continue
-		case base != 16 && (c == 'e' || c == 'E' || c == 'p' || c == 'P'):
+		case base == 10 && (c == 'e' || c == 'E' || c == 'p' || c == 'P'),
+			base == 16 && (c == 'p' || c == 'P'): // hexadecimal float, e.g. 0x1.8p3
 			if hasExp {
-				return errors.New("invalid number")
+				return l.invalid("invalid number")
 			}
 			i++
 			if l.peekOk(i) && (l.peekN(i) == '+' || l.peekN(i) == '-') {
 				i++
 			}
 			if !l.peekOk(i) || !IsDigit(l.peekN(i)) {
-				return errors.New("exponent part should contain at least one digit")
+				return l.invalid("exponent part should contain at least one digit")
+			}
+			for l.peekOk(i) && IsDigit(l.peekN(i)) {
+				i++
+			}
+			if base == 16 {
+				tokenKind = TokenFloat
 			}
 			hasExp = true
 			continue
 		}
 		break
 	}
-	if (l.peekOk(i) && IsIdentPart(l.peekN(i))) || !hasNumberPart {
-		return errors.New("invalid number")
+	if (l.peekOk(i) && IsIdentPart(l.peekN(i))) || !hasDigit {
+		return l.invalid("invalid number")
 	}
 	l.lastToken = &Token{
 		Kind:   tokenKind,
@@ -121,86 +185,200 @@ func (l *Lexer) consumeNumber() error {
 	return nil
 }
 
-func (l *Lexer) consumeIdent(_ Pos) error {
-	token := &Token{}
-	isUnquoted := false
-	if l.peekOk(0) && l.peekN(0) == '`' {
-		l.skipN(1)
-		isUnquoted = true
+// consumeNumberWord consumes a signed inf, infinity or nan, which takes
+// n bytes. The unsigned words are identifiers or keywords, which the
+// parser turns into numbers in expressions.
+func (l *Lexer) consumeNumberWord(n int) error {
+	l.lastToken = &Token{
+		Kind:   TokenFloat,
+		String: l.slice(0, n),
+		Pos:    Pos(l.current),
+		End:    Pos(l.current + n),
+		Base:   10,
 	}
+	l.skipN(n)
+	return nil
+}
 
-	i := 0
-	if !isUnquoted {
-		if l.peekN(i) == '$' {
-			i++
-		}
-		for l.peekOk(i) && IsIdentPart(l.peekN(i)) {
-			i++
-		}
-	} else {
-		for l.peekOk(i) && l.peekN(i) != '`' {
-			i++
-		}
-		if !l.peekOk(i) || l.peekN(i) != '`' {
-			return fmt.Errorf("unclosed quoted identifier: %s", l.slice(0, i))
+// numberWordLen returns the length of the inf, infinity or nan word that
+// s starts with, or 0 if it doesn't start with one.
+func numberWordLen(s string) int {
+	for _, word := range []string{"infinity", "inf", "nan"} {
+		if len(s) >= len(word) && strings.EqualFold(s[:len(word)], word) &&
+			(len(s) == len(word) || !IsIdentPart(s[len(word)])) {
+			return len(word)
 		}
 	}
-	slice := l.slice(0, i)
-	if !isUnquoted && l.isKeyword(strings.ToUpper(slice)) {
-		token.Kind = TokenKeyword
-	} else {
-		token.Kind = TokenIdent
+	return 0
+}
+
+func isNumberWord(s string) bool {
+	return s != "" && numberWordLen(s) == len(s)
+}
+
+func isDigitOf(c byte, base int) bool {
+	switch base {
+	case 2:
+		return c == '0' || c == '1'
+	case 16:
+		return IsHexDigit(c)
+	default:
+		return IsDigit(c)
 	}
-	token.Pos = Pos(l.current)
-	token.End = Pos(l.current + i)
-	token.String = slice
-	token.Unquoted = isUnquoted
-	l.lastToken = token
+}
 
-	l.skipN(i)
-	if isUnquoted {
-		l.skipN(1)
+// isElementAccess reports whether a '.' at the current position follows
+// prev without space, and prev can be followed by a tuple element access
+// like .1: an identifier, a closing bracket or another tuple index.
+func (l *Lexer) isElementAccess(prev *Token) bool {
+	if prev == nil || !l.adjacent(prev) {
+		return false
+	}
+	switch prev.Kind {
+	case TokenIdent, TokenKeyword, TokenInt, ")", "]":
+		return true
+	default:
+		return false
 	}
-	return nil
 }
 
-func (l *Lexer) consumeSingleLineComment() {
-	l.skipN(2)
+// adjacent reports whether the current position is right after token,
+// including the closing quote of a quoted identifier.
+func (l *Lexer) adjacent(token *Token) bool {
+	end := int(token.End)
+	if token.Unquoted {
+		end++
+	}
+	return l.current == end
+}
+
+// consumeTupleIndex consumes the index of a tuple element access like
+// tup.1, which is a decimal integer even if a dot follows, as in tup.1.2.
+func (l *Lexer) consumeTupleIndex() error {
 	i := 0
-	for l.peekOk(i) && l.peekN(i) != '\r' && l.peekN(i) != '\n' {
+	for l.peekOk(i) && IsDigit(l.peekN(i)) {
 		i++
 	}
-	l.skipN(i + 1)
+	if l.peekOk(i) && IsIdentPart(l.peekN(i)) {
+		return l.invalid("invalid tuple index")
+	}
+	l.lastToken = &Token{
+		Kind:   TokenInt,
+		String: l.slice(0, i),
+		Pos:    Pos(l.current),
+		End:    Pos(l.current + i),
+		Base:   10,
+	}
+	l.skipN(i)
+	return nil
 }
 
-func (l *Lexer) consumeMultiLineComment() {
-	l.skipN(2)
+func (l *Lexer) consumeIdent(_ Pos) error {
+	if l.peekN(0) == '`' || l.peekN(0) == '"' {
+		return l.consumeQuotedIdent()
+	}
 	i := 0
-	for !l.isEOF() {
-		if l.peekOk(i+1) && l.peekN(i) == '*' && l.peekN(i+1) == '/' {
-			i += 2
-			break
-		}
+	if l.peekN(i) == '$' {
+		i++
+	}
+	for l.peekOk(i) && IsIdentPart(l.peekN(i)) {
 		i++
 	}
+	slice := l.slice(0, i)
+	token := &Token{
+		Kind:   TokenIdent,
+		String: slice,
+		Pos:    Pos(l.current),
+		End:    Pos(l.current + i),
+	}
+	if l.isKeyword(strings.ToUpper(slice)) {
+		token.Kind = TokenKeyword
+	}
+	l.lastToken = token
 	l.skipN(i)
+	return nil
 }
 
-func (l *Lexer) consumeString(isSingleQuote bool) error {
-	i := 1
-	endChar := byte('\'')
-	if !isSingleQuote {
-		endChar = '"'
+// consumeQuotedIdent consumes an identifier quoted with backticks or
+// double quotes, which are never keywords.
+func (l *Lexer) consumeQuotedIdent() error {
+	value, i := l.scanQuoted()
+	if i < 0 {
+		return l.unterminated("unclosed quoted identifier: %s", l.slice(1, len(l.input)-l.current))
 	}
-	for l.peekOk(i) && l.peekN(i) != endChar {
+	l.lastToken = &Token{
+		Kind:     TokenIdent,
+		String:   value,
+		Pos:      Pos(l.current + 1),
+		End:      Pos(l.current + i),
+		Unquoted: true,
+	}
+	l.skipN(i + 1)
+	return nil
+}
+
+func (l *Lexer) consumeSingleLineComment() {
+	i := 2
+	for l.peekOk(i) && l.peekN(i) != '\r' && l.peekN(i) != '\n' {
 		i++
 	}
-	if !l.peekOk(i) {
-		return errors.New("invalid string")
+	l.addComment(i)
+}
+
+func (l *Lexer) consumeMultiLineComment() error {
+	n := blockCommentLen(l.input[l.current:])
+	if n < 0 {
+		return l.unterminated("unterminated comment")
+	}
+	l.addComment(n)
+	return nil
+}
+
+// blockCommentLen returns the length of the block comment s starts with,
+// which may contain nested block comments, or -1 if s ends before it does.
+func blockCommentLen(s string) int {
+	depth := 0
+	for i := 0; i+1 < len(s); i++ {
+		switch {
+		case s[i] == '/' && s[i+1] == '*':
+			depth++
+			i++
+		case s[i] == '*' && s[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// isHashComment reports whether s starts with a comment like "# text" or
+// "#!text", which ClickHouse accepts besides "-- text".
+func isHashComment(s string) bool {
+	return len(s) > 1 && s[0] == '#' && (s[1] == ' ' || s[1] == '!')
+}
+
+// addComment records the comment in the next n bytes of the input and skips it.
+func (l *Lexer) addComment(n int) {
+	l.comments = append(l.comments, &Token{
+		Pos:    Pos(l.current),
+		End:    Pos(l.current + n),
+		Kind:   TokenComment,
+		String: l.slice(0, n),
+	})
+	l.skipN(n)
+}
+
+func (l *Lexer) consumeString() error {
+	value, i := l.scanQuoted()
+	if i < 0 {
+		return l.unterminated("unterminated string")
 	}
 	l.lastToken = &Token{
 		Kind:   TokenString,
-		String: l.slice(1, i),
+		String: value,
 		Pos:    Pos(l.current + 1),
 		End:    Pos(l.current + i),
 	}
@@ -208,7 +386,83 @@ func (l *Lexer) consumeString(isSingleQuote bool) error {
 	return nil
 }
 
-func (l *Lexer) skipComments() {
+// consumeHeredoc consumes a heredoc string like $tag$...$tag$, whose
+// delimiter is the next n bytes. The text between the delimiters is taken
+// literally.
+func (l *Lexer) consumeHeredoc(n int) error {
+	delimiter := l.slice(0, n)
+	i := strings.Index(l.input[l.current+n:], delimiter)
+	if i < 0 {
+		return l.unterminated("unterminated heredoc string: %s", delimiter)
+	}
+	l.lastToken = &Token{
+		Kind:    TokenString,
+		String:  l.slice(n, n+i),
+		Pos:     Pos(l.current + n),
+		End:     Pos(l.current + n + i),
+		Heredoc: delimiter,
+	}
+	l.skipN(2*n + i)
+	return nil
+}
+
+// heredocDelimiter returns the length of the heredoc delimiter, $$ or $tag$
+// where tag is made of identifier characters, at the start of s, or 0 if s
+// doesn't start with one.
+func heredocDelimiter(s string) int {
+	if len(s) == 0 || s[0] != '$' {
+		return 0
+	}
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '$':
+			return i + 1
+		case !IsIdentPart(s[i]):
+			return 0
+		}
+	}
+	return 0
+}
+
+// scanQuoted decodes the quoted text starting at the current position,
+// where backslash escapes and doubled quotes stand for a single character.
+// It returns the offset of the closing quote, or -1 if there is none. An
+// invalid escape sequence is kept as is and recorded in l.err.
+func (l *Lexer) scanQuoted() (string, int) {
+	quote := l.peekN(0)
+	var value strings.Builder
+	i := 1
+	for l.peekOk(i) {
+		c := l.peekN(i)
+		if c == '\\' {
+			if !l.peekOk(i + 1) {
+				break
+			}
+			decoded, n := unescapeSequence(l.input[l.current+i:])
+			if n == 0 {
+				// keep lexing so that the parser can still find the end of
+				// the statement; the error is reported once it is parsed
+				_ = l.invalidAt(Pos(l.current+i), "invalid hexadecimal escape sequence")
+				decoded, n = "\\", 1
+			}
+			value.WriteString(decoded)
+			i += n
+			continue
+		}
+		if c == quote {
+			if !l.peekOk(i+1) || l.peekN(i+1) != quote {
+				return value.String(), i
+			}
+			// a doubled quote stands for the quote itself
+			i++
+		}
+		value.WriteByte(c)
+		i++
+	}
+	return "", -1
+}
+
+func (l *Lexer) skipComments() error {
 	for !l.isEOF() {
 		switch l.peekN(0) {
 		case '-':
@@ -216,25 +470,36 @@ func (l *Lexer) skipComments() {
 				l.consumeSingleLineComment()
 				continue
 			}
-			return
+			return nil
+		case '#':
+			if isHashComment(l.input[l.current:]) {
+				l.consumeSingleLineComment()
+				continue
+			}
+			return nil
 		case '/': // multi-line comment
 			if l.peekOk(1) && l.peekN(1) == '*' {
-				l.consumeMultiLineComment()
+				if err := l.consumeMultiLineComment(); err != nil {
+					return err
+				}
 				continue
 			}
-			return
-		case '\r', '\n':
-			// skip \r\n or \n\r
-			l.skipN(1)
+			return nil
 		default:
-			return
+			r, size := utf8.DecodeRuneInString(l.input[l.current:])
+			if !unicode.IsSpace(r) {
+				return nil
+			}
+			l.skipN(size)
 		}
 	}
+	return nil
 }
 
 func (l *Lexer) peekToken() (*Token, error) {
 	saveToken := l.lastToken
 	saveCurrent := l.current
+	saveComments := len(l.comments)
 	if err := l.consumeToken(); err != nil {
 		return nil, err
 	}
@@ -242,14 +507,44 @@ func (l *Lexer) peekToken() (*Token, error) {
 
 	l.lastToken = saveToken
 	l.current = saveCurrent
+	l.comments = l.comments[:saveComments]
 	return token, nil
 }
 
+// peekTokens returns up to n tokens after the last token without
+// consuming them, fewer if the input ends before.
+func (l *Lexer) peekTokens(n int) ([]*Token, error) {
+	saveToken := l.lastToken
+	saveCurrent := l.current
+	saveComments := len(l.comments)
+	saveErr, saveTruncated := l.err, l.truncated
+	defer func() {
+		l.lastToken = saveToken
+		l.current = saveCurrent
+		l.comments = l.comments[:saveComments]
+		l.err, l.truncated = saveErr, saveTruncated
+	}()
+	tokens := make([]*Token, 0, n)
+	for len(tokens) < n {
+		if err := l.consumeToken(); err != nil {
+			return nil, err
+		}
+		if l.lastToken == nil {
+			break
+		}
+		tokens = append(tokens, l.lastToken)
+	}
+	return tokens, nil
+}
+
 func (l *Lexer) consumeToken() error {
 	l.skipSpace()
+	prev := l.lastToken
 	// clear last token
 	l.lastToken = nil
-	l.skipComments()
+	if err := l.skipComments(); err != nil {
+		return err
+	}
 	l.skipSpace()
 	if l.isEOF() {
 		return nil
@@ -271,7 +566,7 @@ func (l *Lexer) consumeToken() error {
 		}
 
 	case '+', '-':
-		if l.peekOk(1) && IsDigit(l.peekN(1)) {
+		if l.peekOk(1) && IsDigit(l.peekN(1)) || numberWordLen(l.input[l.current+1:]) > 0 {
 			return l.consumeNumber()
 		} else if l.peekOk(1) && l.peekN(1) == '>' {
 			l.lastToken = &Token{
@@ -284,13 +579,19 @@ func (l *Lexer) consumeToken() error {
 			return nil
 		}
 	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		if prev != nil && prev.Kind == "." && l.adjacent(prev) {
+			return l.consumeTupleIndex()
+		}
 		return l.consumeNumber()
-	case '`', '$':
+	case '$':
+		if n := heredocDelimiter(l.input[l.current:]); n > 0 {
+			return l.consumeHeredoc(n)
+		}
+		return l.consumeIdent(Pos(l.current))
+	case '`', '"':
 		return l.consumeIdent(Pos(l.current))
 	case '\'':
-		return l.consumeString(true)
-	case '"':
-		return l.consumeString(false)
+		return l.consumeString()
 	case ':':
 		if l.peekOk(1) && l.peekN(1) == ':' {
 			l.lastToken = &Token{
@@ -303,8 +604,9 @@ func (l *Lexer) consumeToken() error {
 			return nil
 		}
 	case '.':
-		// check if the next token is a number. If so, parse it as a float number
-		if l.peekOk(1) && IsDigit(l.peekN(1)) {
+		// check if the next token is a number. If so, parse it as a float number,
+		// unless the dot accesses an element of what precedes it, e.g. tup.1
+		if l.peekOk(1) && IsDigit(l.peekN(1)) && !l.isElementAccess(prev) {
 			return l.consumeNumber()
 		}
 		// check if the previous lastToken is an Ident. If so, it's a field name.
@@ -22,13 +22,53 @@ func TestConsumeComment(t *testing.T) {
 		"/* hello world */ /* hello world */\n",
 		"/* hello world */ /* hello world */\r\n",
 		"/* hello world */ /* hello world */\r",
+		"/* hello /* nested */ world */",
+		"/* /* /* deeply */ nested */ */\n",
+		"# hello world",
+		"#!/usr/bin/env clickhouse-client\n",
 	}
 	for _, c := range comments {
 		lexer := NewLexer(c)
 		err := lexer.consumeToken()
 		require.NoError(t, err)
+		require.Nil(t, lexer.lastToken, c)
+		require.NotEmpty(t, lexer.comments, c)
 	}
 
+	lexer := NewLexer("/* a /* b */ c */ SELECT")
+	require.NoError(t, lexer.consumeToken())
+	require.Equal(t, "/* a /* b */ c */", lexer.comments[0].String)
+	require.Equal(t, "SELECT", lexer.lastToken.String)
+}
+
+func TestConsumeComment_Unterminated(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		pos     Pos
+		message string
+	}{
+		{input: "SELECT 1 /* no end", pos: 9, message: "unterminated comment"},
+		{input: "SELECT 1 /* outer /* inner */", pos: 9, message: "unterminated comment"},
+		{input: "SELECT 'abc", pos: 7, message: "unterminated string"},
+		{input: "SELECT $$abc", pos: 7, message: "unterminated heredoc string: $$"},
+		{input: "SELECT `abc", pos: 7, message: "unclosed quoted identifier: abc"},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			lexer := NewLexer(tc.input)
+			var err error
+			for err == nil && !lexer.isEOF() {
+				err = lexer.consumeToken()
+			}
+			require.EqualError(t, err, tc.message)
+			require.Equal(t, err, lexer.err)
+			require.Equal(t, tc.pos, err.(*positionedError).pos)
+		})
+	}
+
+	// '#' only starts a comment when followed by a space or '!'
+	lexer := NewLexer("#abc")
+	require.NoError(t, lexer.consumeToken())
+	require.Equal(t, TokenKind("#"), lexer.lastToken.Kind)
 }
 
 func TestConsumeString(t *testing.T) {
@@ -46,6 +86,160 @@ func TestConsumeString(t *testing.T) {
 	}
 }
 
+func TestConsumeString_Escapes(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{`'it''s'`, "it's"},
+		{`'a\'b'`, "a'b"},
+		{`'a\\b'`, `a\b`},
+		{`'line\nbreak\ttab'`, "line\nbreak\ttab"},
+		{`'\x41\x4a\x6b'`, "AJk"},
+		{`'\0\b\f\r\a\v'`, "\x00\b\f\r\a\v"},
+		{`'\d+\.'`, "d+."},
+		{`''`, ""},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			lexer := NewLexer(tc.input)
+			require.NoError(t, lexer.consumeToken())
+			require.Equal(t, TokenString, lexer.lastToken.Kind)
+			require.Equal(t, tc.expected, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		})
+	}
+
+	for _, input := range []string{`'abc`, `'abc\'`, `'abc\`} {
+		require.Error(t, NewLexer(input).consumeToken(), input)
+	}
+
+	// an invalid escape is recorded at the backslash, but the string is
+	// still consumed as a whole
+	for _, input := range []string{`'a\x4'`, `'a\xZZ'`} {
+		lexer := NewLexer(input)
+		require.NoError(t, lexer.consumeToken(), input)
+		require.True(t, lexer.isEOF(), input)
+		require.Equal(t, &positionedError{pos: 2, msg: "invalid hexadecimal escape sequence"}, lexer.err, input)
+	}
+}
+
+func TestConsumeQuotedIdent(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{"`col name`", "col name"},
+		{`"col name"`, "col name"},
+		{`"select"`, "select"},
+		{`"say ""hi"""`, `say "hi"`},
+		{`"a\"b"`, `a"b`},
+		{"`a``b\\\\c`", "a`b\\c"},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			lexer := NewLexer(tc.input)
+			require.NoError(t, lexer.consumeToken())
+			require.Equal(t, TokenIdent, lexer.lastToken.Kind)
+			require.True(t, lexer.lastToken.Unquoted)
+			require.Equal(t, tc.expected, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		})
+	}
+
+	err := NewLexer(`"abc`).consumeToken()
+	require.EqualError(t, err, "unclosed quoted identifier: abc")
+}
+
+func TestIdent_Quote(t *testing.T) {
+	stmt := parseSingleStatement(t, "SELECT \"Total Sales\", `order`, \"a\"\"b\", c FROM \"sales\".\"orders\"")
+	query := stmt.(*SelectQuery)
+	var quotes []IdentQuote
+	var names []string
+	Inspect(query.SelectColumns, func(node Expr) bool {
+		if ident, ok := node.(*Ident); ok {
+			quotes = append(quotes, ident.Quote)
+			names = append(names, ident.Name)
+		}
+		return true
+	})
+	require.Equal(t, []string{"Total Sales", "order", `a"b`, "c"}, names)
+	require.Equal(t, []IdentQuote{IdentQuoteDoubleQuote, IdentQuoteBacktick, IdentQuoteDoubleQuote, ""}, quotes)
+	require.Equal(t, "\"Total Sales\", `order`, \"a\\\"b\", c", query.SelectColumns.String(0))
+	require.Contains(t, query.String(0), "\"sales\".\"orders\"")
+
+	reparsed := parseSingleStatement(t, query.String(0))
+	require.True(t, Equal(query, reparsed, EqualOptions{IgnorePos: true}))
+	require.Equal(t, "`a b`", (&Ident{Name: "a b", Unquoted: true}).String(0))
+}
+
+func TestConsumeHeredoc(t *testing.T) {
+	for _, tc := range []struct {
+		input     string
+		expected  string
+		delimiter string
+	}{
+		{"$$hello$$", "hello", "$$"},
+		{"$$$$", "", "$$"},
+		{"$tag$it's \\n $$ raw$tag$", "it's \\n $$ raw", "$tag$"},
+		{"$a_1$x$a_1$", "x", "$a_1$"},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			lexer := NewLexer(tc.input)
+			require.NoError(t, lexer.consumeToken())
+			require.Equal(t, TokenString, lexer.lastToken.Kind)
+			require.Equal(t, tc.expected, lexer.lastToken.String)
+			require.Equal(t, tc.delimiter, lexer.lastToken.Heredoc)
+			require.Equal(t, Pos(0), tokenStart(lexer.lastToken))
+			require.True(t, lexer.isEOF())
+		})
+	}
+
+	require.EqualError(t, NewLexer("$body$ abc $bod$").consumeToken(), "unterminated heredoc string: $body$")
+
+	lexer := NewLexer("$abc")
+	require.NoError(t, lexer.consumeToken())
+	require.Equal(t, TokenIdent, lexer.lastToken.Kind)
+	require.Equal(t, "$abc", lexer.lastToken.String)
+}
+
+func TestStringLiteral_Heredoc(t *testing.T) {
+	sql := "SELECT $body$ 'x' $body$ AS a"
+	stmt := parseSingleStatement(t, sql)
+	literal := stmt.(*SelectQuery).SelectColumns.Items[0].(*AliasExpr).Expr.(*StringLiteral)
+	require.Equal(t, " 'x' ", literal.Literal)
+	require.Equal(t, "$body$", literal.Heredoc)
+	require.Equal(t, "$body$ 'x' $body$", literal.String(0))
+	start, end := NodeRange(sql, literal)
+	require.Equal(t, "$body$ 'x' $body$", sql[start:end])
+
+	literal.Literal = "contains $body$"
+	require.Equal(t, "'contains $body$'", literal.String(0))
+}
+
+func TestStringLiteral_RoundTrip(t *testing.T) {
+	for _, value := range []string{
+		"it's",
+		`back\slash`,
+		"new\nline\r\n\ttab",
+		"nul\x00 bell\a del\x7f",
+		"unicode: 日本語",
+		`'\''`,
+	} {
+		t.Run(value, func(t *testing.T) {
+			sql := "SELECT " + (&StringLiteral{Literal: value}).String(0)
+			stmt := parseSingleStatement(t, sql)
+			literal := stmt.(*SelectQuery).SelectColumns.Items[0].(*StringLiteral)
+			require.Equal(t, value, literal.Literal)
+			require.Equal(t, sql[len("SELECT '"):len(sql)-1], literal.Raw)
+		})
+	}
+
+	stmt := parseSingleStatement(t, `SELECT 'it''s \x41'`)
+	literal := stmt.(*SelectQuery).SelectColumns.Items[0].(*StringLiteral)
+	require.Equal(t, "it's A", literal.Literal)
+	require.Equal(t, `it''s \x41`, literal.Raw)
+	require.Equal(t, `'it\'s A'`, literal.String(0))
+}
+
 func TestConsumeNumber(t *testing.T) {
 	t.Run("Integer number", func(t *testing.T) {
 		integers := []string{
@@ -84,22 +278,96 @@ func TestConsumeNumber(t *testing.T) {
 		}
 	})
 
+	t.Run("Binary number", func(t *testing.T) {
+		numbers := []string{
+			"0b1010",
+			"0B1",
+			"-0b1_0",
+		}
+		for _, n := range numbers {
+			lexer := NewLexer(n)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenInt, lexer.lastToken.Kind)
+			require.Equal(t, 2, lexer.lastToken.Base)
+			require.Equal(t, n, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
+	t.Run("Digit separators", func(t *testing.T) {
+		numbers := map[string]TokenKind{
+			"1_000_000":   TokenInt,
+			"0xFF_FF":     TokenInt,
+			"1_000.000_1": TokenFloat,
+			"-1_0":        TokenInt,
+		}
+		for n, kind := range numbers {
+			lexer := NewLexer(n)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, kind, lexer.lastToken.Kind)
+			require.Equal(t, n, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
+	t.Run("Hexadecimal float number", func(t *testing.T) {
+		numbers := []string{
+			"0x1.8p3",
+			"0x1p-2",
+			"0XA.Bp+1",
+			"-0x1.8",
+		}
+		for _, n := range numbers {
+			lexer := NewLexer(n)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenFloat, lexer.lastToken.Kind)
+			require.Equal(t, 16, lexer.lastToken.Base)
+			require.Equal(t, n, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		}
+	})
+
+	t.Run("Signed inf and nan", func(t *testing.T) {
+		for _, n := range []string{"-inf", "+inf", "-Infinity", "-nan", "+NaN"} {
+			lexer := NewLexer(n)
+			err := lexer.consumeToken()
+			require.NoError(t, err)
+			require.Equal(t, TokenFloat, lexer.lastToken.Kind)
+			require.Equal(t, n, lexer.lastToken.String)
+			require.True(t, lexer.isEOF())
+		}
+
+		lexer := NewLexer("-info")
+		require.NoError(t, lexer.consumeToken())
+		require.Equal(t, TokenKind("-"), lexer.lastToken.Kind)
+	})
+
 	t.Run("Invalid number", func(t *testing.T) {
-		invalidNumbers := []string{
-			"123e",
-			"123e+",
-			"123e-",
-			"123e",
-			"123E",
-			"123E+",
-			"123E-",
-			"0x",
-			"0xg",
-		}
-		for _, n := range invalidNumbers {
+		invalidNumbers := map[string]string{
+			"123e":  "exponent part should contain at least one digit",
+			"123e+": "exponent part should contain at least one digit",
+			"123e-": "exponent part should contain at least one digit",
+			"123E":  "exponent part should contain at least one digit",
+			"123E+": "exponent part should contain at least one digit",
+			"123E-": "exponent part should contain at least one digit",
+			"0x":    "invalid number",
+			"0xg":   "invalid number",
+			"0b":    "invalid number",
+			"0b102": "invalid number",
+			"1_":    "invalid number",
+			"1__0":  "invalid number",
+			"0x_1":  "invalid number",
+			"0x1p":  "exponent part should contain at least one digit",
+		}
+		for n, message := range invalidNumbers {
 			lexer := NewLexer(n)
 			err := lexer.consumeToken()
-			require.Error(t, err)
+			require.EqualError(t, err, message, n)
+			require.Equal(t, err, lexer.err)
+			require.Equal(t, Pos(0), err.(*positionedError).pos)
 		}
 	})
 
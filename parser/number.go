@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// IsNegative reports whether the number is written with a minus sign,
+// e.g. -1 or -inf.
+func (n *NumberLiteral) IsNegative() bool {
+	return strings.HasPrefix(n.Literal, "-")
+}
+
+// IsFloat reports whether the number is a floating-point literal, e.g.
+// 1.5, 1e3, 0x1.8p3, inf or nan.
+func (n *NumberLiteral) IsFloat() bool {
+	_, digits, base := n.parts()
+	if isNumberWord(digits) {
+		return true
+	}
+	if base == 16 {
+		return strings.ContainsAny(digits, ".pP")
+	}
+	return strings.ContainsAny(digits, ".eEpP")
+}
+
+// Int64 returns the value of an integer literal that fits in an Int64.
+func (n *NumberLiteral) Int64() (int64, error) {
+	value, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsInt64() {
+		return 0, fmt.Errorf("number %s is out of the Int64 range", n.Literal)
+	}
+	return value.Int64(), nil
+}
+
+// Uint64 returns the value of a non-negative integer literal that fits in
+// a UInt64.
+func (n *NumberLiteral) Uint64() (uint64, error) {
+	value, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsUint64() {
+		return 0, fmt.Errorf("number %s is out of the UInt64 range", n.Literal)
+	}
+	return value.Uint64(), nil
+}
+
+// BigInt returns the value of an integer literal of any size, e.g. an
+// Int128 or UInt256 value.
+func (n *NumberLiteral) BigInt() (*big.Int, error) {
+	if err := n.checkInteger(); err != nil {
+		return nil, err
+	}
+	sign, digits, base := n.parts()
+	value, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer literal: %s", n.Literal)
+	}
+	if sign == "-" {
+		value.Neg(value)
+	}
+	return value, nil
+}
+
+// Float64 returns the value of the number as a Float64, rounding it if it
+// can't be represented exactly. inf and nan give the IEEE 754 values.
+func (n *NumberLiteral) Float64() (float64, error) {
+	sign, digits, base := n.parts()
+	switch {
+	case isNumberWord(digits):
+		if strings.EqualFold(digits, "nan") {
+			return math.NaN(), nil
+		}
+		if sign == "-" {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case base == 16:
+		if !strings.ContainsAny(digits, "pP") {
+			digits += "p0"
+		}
+		return strconv.ParseFloat(sign+"0x"+digits, 64)
+	case base == 2:
+		value, err := n.BigInt()
+		if err != nil {
+			return 0, err
+		}
+		f, _ := new(big.Float).SetInt(value).Float64()
+		return f, nil
+	default:
+		return strconv.ParseFloat(sign+strings.NewReplacer("p", "e", "P", "e").Replace(digits), 64)
+	}
+}
+
+func (n *NumberLiteral) checkInteger() error {
+	if n.IsFloat() {
+		return fmt.Errorf("number %s is not an integer literal", n.Literal)
+	}
+	return nil
+}
+
+// parts splits the literal into its sign, its digits without the base
+// prefix and digit separators, and its base.
+func (n *NumberLiteral) parts() (sign, digits string, base int) {
+	digits = strings.ReplaceAll(n.Literal, "_", "")
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	base = 10
+	if len(digits) > 1 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			digits, base = digits[2:], 16
+		case 'b', 'B':
+			digits, base = digits[2:], 2
+		}
+	}
+	return sign, digits, base
+}
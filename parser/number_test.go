@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseSelectItem(t *testing.T, sql string) Expr {
+	t.Helper()
+	stmts, err := NewParser("SELECT " + sql).ParseStatements()
+	require.NoError(t, err)
+	return stmts[0].(*SelectQuery).SelectColumns.Items[0]
+}
+
+func parseNumberLiteral(t *testing.T, sql string) *NumberLiteral {
+	t.Helper()
+	expr := parseSelectItem(t, sql)
+	number, ok := expr.(*NumberLiteral)
+	require.True(t, ok, "%s is %T", sql, expr)
+	return number
+}
+
+func TestNumberLiteral_Integers(t *testing.T) {
+	for _, tc := range []struct {
+		sql   string
+		value int64
+	}{
+		{sql: "0", value: 0},
+		{sql: "42", value: 42},
+		{sql: "-42", value: -42},
+		{sql: "+7", value: 7},
+		{sql: "1_000_000", value: 1000000},
+		{sql: "0xFF", value: 255},
+		{sql: "-0x10", value: -16},
+		{sql: "0b1010", value: 10},
+		{sql: "0b1111_0000", value: 240},
+		{sql: "9223372036854775807", value: math.MaxInt64},
+		{sql: "-9223372036854775808", value: math.MinInt64},
+	} {
+		t.Run(tc.sql, func(t *testing.T) {
+			number := parseNumberLiteral(t, tc.sql)
+			require.False(t, number.IsFloat())
+			require.Equal(t, tc.value < 0, number.IsNegative())
+
+			value, err := number.Int64()
+			require.NoError(t, err)
+			require.Equal(t, tc.value, value)
+
+			bigValue, err := number.BigInt()
+			require.NoError(t, err)
+			require.Equal(t, big.NewInt(tc.value), bigValue)
+
+			f, err := number.Float64()
+			require.NoError(t, err)
+			require.Equal(t, float64(tc.value), f)
+		})
+	}
+}
+
+func TestNumberLiteral_Uint64(t *testing.T) {
+	number := parseNumberLiteral(t, "18446744073709551615")
+	_, err := number.Int64()
+	require.EqualError(t, err, "number 18446744073709551615 is out of the Int64 range")
+	value, err := number.Uint64()
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint64), value)
+
+	_, err = parseNumberLiteral(t, "-1").Uint64()
+	require.EqualError(t, err, "number -1 is out of the UInt64 range")
+	_, err = parseNumberLiteral(t, "0x1_0000_0000_0000_0000").Uint64()
+	require.Error(t, err)
+}
+
+func TestNumberLiteral_BigInt(t *testing.T) {
+	// the largest Int256 value
+	sql := "57896044618658097711785492504343953926634992332820282019728792003956564819967"
+	expected, ok := new(big.Int).SetString(sql, 10)
+	require.True(t, ok)
+	value, err := parseNumberLiteral(t, sql).BigInt()
+	require.NoError(t, err)
+	require.Equal(t, expected, value)
+
+	value, err = parseNumberLiteral(t, "-0xFFFF_FFFF_FFFF_FFFF_FFFF_FFFF_FFFF_FFFF").BigInt()
+	require.NoError(t, err)
+	expected, ok = new(big.Int).SetString("-340282366920938463463374607431768211455", 10)
+	require.True(t, ok)
+	require.Equal(t, expected, value)
+
+	_, err = parseNumberLiteral(t, "1.5").BigInt()
+	require.EqualError(t, err, "number 1.5 is not an integer literal")
+	_, err = parseNumberLiteral(t, "1e3").Int64()
+	require.EqualError(t, err, "number 1e3 is not an integer literal")
+}
+
+func TestNumberLiteral_Floats(t *testing.T) {
+	for _, tc := range []struct {
+		sql   string
+		value float64
+	}{
+		{sql: "1.5", value: 1.5},
+		{sql: ".25", value: 0.25},
+		{sql: "-2.5e3", value: -2500},
+		{sql: "1e-2", value: 0.01},
+		{sql: "1_000.000_5", value: 1000.0005},
+		{sql: "0x1.8p3", value: 12},
+		{sql: "0x1p-2", value: 0.25},
+		{sql: "-0x1.8", value: -1.5},
+		{sql: "inf", value: math.Inf(1)},
+		{sql: "INFINITY", value: math.Inf(1)},
+		{sql: "-inf", value: math.Inf(-1)},
+	} {
+		t.Run(tc.sql, func(t *testing.T) {
+			number := parseNumberLiteral(t, tc.sql)
+			require.True(t, number.IsFloat())
+			require.Equal(t, tc.value < 0, number.IsNegative())
+			value, err := number.Float64()
+			require.NoError(t, err)
+			require.Equal(t, tc.value, value)
+			_, err = number.Int64()
+			require.Error(t, err)
+		})
+	}
+
+	for _, sql := range []string{"nan", "-nan", "NaN"} {
+		value, err := parseNumberLiteral(t, sql).Float64()
+		require.NoError(t, err)
+		require.True(t, math.IsNaN(value))
+	}
+}
+
+func TestNumberLiteral_NumberWordsAsIdentifiers(t *testing.T) {
+	stmts, err := NewParser("CREATE TABLE t (inf Float64, nan Float64) ENGINE = Memory;").ParseStatements()
+	require.NoError(t, err)
+	columns := stmts[0].(*CreateTable).TableSchema.Columns
+	require.Equal(t, "inf", columns[0].(*Column).Name.Name)
+	require.Equal(t, "nan", columns[1].(*Column).Name.Name)
+
+	require.IsType(t, &Ident{}, parseSelectItem(t, "`inf`"))
+}
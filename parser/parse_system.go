@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strings"
 )
 
@@ -44,7 +43,7 @@ func (p *Parser) parseSystemFlushExpr(pos Pos) (*SystemFlushExpr, error) {
 			Distributed:  distributed,
 		}, nil
 	default:
-		return nil, fmt.Errorf("expected LOGS|DISTRIBUTED")
+		return nil, p.errExpected(KeywordLogs, KeywordDistributed)
 	}
 }
 
@@ -84,7 +83,7 @@ func (p *Parser) parseSystemReloadExpr(pos Pos) (*SystemReloadExpr, error) {
 			Type:         "EMBEDDED DICTIONARIES",
 		}, nil
 	default:
-		return nil, fmt.Errorf("expected DICTIONARIES|CONFIG")
+		return nil, p.errExpected(KeywordDictionaries, KeywordConfig)
 	}
 }
 
@@ -107,7 +106,7 @@ func (p *Parser) parseSystemSyncExpr(pos Pos) (*SystemSyncExpr, error) {
 
 func (p *Parser) parseSystemCtrlExpr(pos Pos) (*SystemCtrlExpr, error) {
 	if !p.matchKeyword(KeywordStart) && !p.matchKeyword(KeywordStop) {
-		return nil, fmt.Errorf("expected START|STOP")
+		return nil, p.errExpected(KeywordStart, KeywordStop)
 	}
 	command := strings.ToUpper(p.last().String)
 	_ = p.lexer.consumeToken()
@@ -128,7 +127,7 @@ func (p *Parser) parseSystemCtrlExpr(pos Pos) (*SystemCtrlExpr, error) {
 				return nil, err
 			}
 		default:
-			return nil, fmt.Errorf("expected SENDS|FETCHES|MERGES|TTL")
+			return nil, p.errExpected(KeywordSends, KeywordFetches, KeywordMerges, KeywordTtl)
 		}
 		cluster, err := p.parseTableIdentifier(p.Pos())
 		if err != nil {
@@ -154,7 +153,7 @@ func (p *Parser) parseSystemCtrlExpr(pos Pos) (*SystemCtrlExpr, error) {
 			Type:         typ,
 		}, nil
 	default:
-		return nil, fmt.Errorf("expected DISTRIBUTED|REPLICATED")
+		return nil, p.errExpected(KeywordDistributed, KeywordReplicated)
 	}
 }
 
@@ -194,7 +193,7 @@ func (p *Parser) parseSystemDropExpr(pos Pos) (*SystemDropExpr, error) {
 			Type:         "COMPILED EXPRESSION CACHE",
 		}, nil
 	default:
-		return nil, fmt.Errorf("expected DNS|MARK|REPLICA|DATABASE|UNCOMPRESSION|COMPILED|QUERY")
+		return nil, p.errExpected(KeywordDNS, KeywordMark, KeywordReplica, KeywordDatabase, "UNCOMPRESSION", KeywordCompiled, KeywordQuery)
 	}
 }
 
@@ -308,7 +307,7 @@ func (p *Parser) parseSystemExpr(pos Pos) (*SystemExpr, error) {
 	case p.matchKeyword(KeywordDrop):
 		expr, err = p.parseSystemDropExpr(p.Pos())
 	default:
-		return nil, fmt.Errorf("expected FLUSH|RELOAD|SYNC|START|STOP")
+		return nil, p.errExpected(KeywordFlush, KeywordReload, KeywordSync, KeywordStart, KeywordStop)
 	}
 	if err != nil {
 		return nil, err
@@ -378,7 +377,7 @@ func (p *Parser) parseRoleName(_ Pos) (*RoleName, error) {
 			OnCluster: onCluster,
 		}, nil
 	default:
-		return nil, fmt.Errorf("expected <ident> or <string>")
+		return nil, p.errExpected(string(TokenIdent), string(TokenString))
 	}
 }
 
@@ -424,6 +423,9 @@ func (p *Parser) parseRoleSetting(_ Pos) (*RoleSetting, error) {
 		}
 
 	}
+	if len(pairs) == 0 {
+		return nil, p.errExpected(string(TokenIdent))
+	}
 	return &RoleSetting{
 		SettingPairs: pairs,
 	}, nil
@@ -519,7 +521,7 @@ func (p *Parser) parserDropUserOrRole(pos Pos) (*DropUserOrRole, error) {
 		target = p.last().String
 		_ = p.lexer.consumeToken()
 	default:
-		return nil, fmt.Errorf("expected USER|ROLE")
+		return nil, p.errExpected(KeywordUser, KeywordRole)
 	}
 
 	ifExists, err := p.tryParseIfExists()
@@ -620,7 +622,7 @@ func (p *Parser) parsePrivilegeAlter(pos Pos) (*PrivilegeExpr, error) {
 		case p.tryConsumeKeyword(KeywordTtl) != nil:
 			keywords = append(keywords, KeywordTtl)
 		default:
-			return nil, fmt.Errorf("expected COLUMN|INDEX")
+			return nil, p.errExpected(KeywordColumn, KeywordIndex)
 		}
 	case p.tryConsumeKeyword(KeywordOrder) != nil:
 		if err := p.consumeKeyword(KeywordBy); err != nil {
@@ -642,7 +644,7 @@ func (p *Parser) parsePrivilegeAlter(pos Pos) (*PrivilegeExpr, error) {
 		case p.tryConsumeKeyword(KeywordRefresh) != nil:
 			keywords = append(keywords, KeywordRefresh)
 		default:
-			return nil, fmt.Errorf("expected MODIFY|REFRESH")
+			return nil, p.errExpected(KeywordModify, KeywordRefresh)
 		}
 	case p.matchKeyword(KeywordMove), p.matchKeyword(KeywordFreeze):
 		keyword := p.last().String
@@ -653,7 +655,11 @@ func (p *Parser) parsePrivilegeAlter(pos Pos) (*PrivilegeExpr, error) {
 		}
 		keywords = append(keywords, KeywordPartition)
 	default:
-		return nil, fmt.Errorf("expected UPDATE|DELETE|ADD|DROP|MODIFY|CLEAR|COMMENT|RENAME|MATERIALIZED|ORDER|SAMPLE|SETTINGS|VIEW|MOVE|FREEZE")
+		return nil, p.errExpected(
+			KeywordUpdate, KeywordDelete, KeywordAdd, KeywordDrop, KeywordModify, KeywordClear,
+			KeywordComment, KeywordRename, KeywordMaterialized, KeywordOrder, KeywordSample,
+			KeywordSettings, KeywordView, KeywordMove, KeywordFreeze,
+		)
 	}
 	return &PrivilegeExpr{
 		PrivilegePos: pos,
@@ -681,7 +687,10 @@ func (p *Parser) parsePrivilegeCreate(pos Pos) (*PrivilegeExpr, error) {
 		}
 		keywords = append(keywords, KeywordRows, KeywordPolicy)
 	default:
-		return nil, fmt.Errorf("expected DATABASE|DICTIONARY|TABLE|FUNCTION|VIEW|USER|ROLE|ROWS")
+		return nil, p.errExpected(
+			KeywordDatabase, KeywordDictionary, KeywordTable, KeywordFunction, KeywordView, KeywordUser,
+			KeywordRole, KeywordRows,
+		)
 	}
 	return &PrivilegeExpr{
 		PrivilegePos: pos,
@@ -699,7 +708,7 @@ func (p *Parser) parsePrivilegeDrop(pos Pos) (*PrivilegeExpr, error) {
 		_ = p.lexer.consumeToken()
 		keywords = append(keywords, keyword)
 	default:
-		return nil, fmt.Errorf("expected DATABASE|DICTIONARY|TABLE|FUNCTION|VIEW")
+		return nil, p.errExpected(KeywordDatabase, KeywordDictionary, KeywordTable, KeywordFunction, KeywordView)
 	}
 	return &PrivilegeExpr{
 		PrivilegePos: pos,
@@ -716,7 +725,7 @@ func (p *Parser) parsePrivilegeShow(pos Pos) (*PrivilegeExpr, error) {
 		_ = p.lexer.consumeToken()
 		keywords = append(keywords, keyword)
 	default:
-		return nil, fmt.Errorf("expected DATABASES|DICTIONARIES|TABLES|COLUMNS")
+		return nil, p.errExpected(KeywordDatabases, KeywordDictionaries, KeywordTables, KeywordColumns)
 	}
 	return &PrivilegeExpr{
 		PrivilegePos: pos,
@@ -746,7 +755,7 @@ func (p *Parser) parsePrivilegeSystem(pos Pos) (*PrivilegeExpr, error) {
 			}
 			keywords = append(keywords, KeywordCache)
 		default:
-			return nil, fmt.Errorf("expected CACHE|MARK|DNS|UNCOMPRESSED")
+			return nil, p.errExpected(KeywordCache, KeywordMark, KeywordDNS, KeywordUncompressed)
 		}
 	case p.tryConsumeKeyword(KeywordReload) != nil:
 		keywords = append(keywords, KeywordReload)
@@ -757,7 +766,7 @@ func (p *Parser) parsePrivilegeSystem(pos Pos) (*PrivilegeExpr, error) {
 			_ = p.lexer.consumeToken()
 			keywords = append(keywords, keyword)
 		default:
-			return nil, fmt.Errorf("expected DICTIONARY|FUNCTION|FUNCTIONS|CONFIG")
+			return nil, p.errExpected(KeywordDictionary, KeywordFunction, KeywordFunctions, KeywordConfig)
 		}
 	case p.tryConsumeKeyword(KeywordFlush) != nil:
 		keywords = append(keywords, KeywordFlush)
@@ -767,7 +776,7 @@ func (p *Parser) parsePrivilegeSystem(pos Pos) (*PrivilegeExpr, error) {
 			_ = p.lexer.consumeToken()
 			keywords = append(keywords, keyword)
 		default:
-			return nil, fmt.Errorf("expected LOGS|DISTRIBUTED")
+			return nil, p.errExpected(KeywordLogs, KeywordDistributed)
 		}
 	case p.tryConsumeKeyword(KeywordTtl) != nil:
 		keywords = append(keywords, KeywordTtl)
@@ -790,7 +799,11 @@ func (p *Parser) parsePrivilegeSystem(pos Pos) (*PrivilegeExpr, error) {
 		}
 		keywords = append(keywords, KeywordQueues)
 	default:
-		return nil, fmt.Errorf("expected QUEUES|SHUTDOWN|MERGES|FETCHES|SENDS|MOVES|CLUSTER|DROP|RELOAD|FLUSH|TTL|SYNC|RESTART|REPLICATION")
+		return nil, p.errExpected(
+			KeywordQueues, KeywordShutdown, KeywordMerges, KeywordFetches, KeywordSends, KeywordMoves,
+			KeywordCluster, KeywordDrop, KeywordReload, KeywordFlush, KeywordTtl, KeywordSync,
+			KeywordRestart, KeywordReplication,
+		)
 	}
 	return &PrivilegeExpr{
 		PrivilegePos: pos,
@@ -859,7 +872,10 @@ func (p *Parser) parsePrivilege(pos Pos) (*PrivilegeExpr, error) {
 			Keywords:     []string{KeywordRole, KeywordAdmin},
 		}, nil
 	}
-	return nil, fmt.Errorf("expected SELECT|INSERT|ALTER|CREATE|DROP|SHOW|KILL|SYSTEM|OPTIMIZE|TRUNCATE")
+	return nil, p.errExpected(
+		KeywordSelect, KeywordInsert, KeywordAlter, KeywordCreate, KeywordDrop, KeywordShow, KeywordKill,
+		KeywordSystem, KeywordOptimize, KeywordTruncate,
+	)
 }
 
 func (p *Parser) parsePrivilegeRoles(_ Pos) ([]*Ident, error) {
@@ -1,10 +1,5 @@
 package parser
 
-import (
-	"errors"
-	"fmt"
-)
-
 func (p *Parser) parseAlterTable(pos Pos) (*AlterTable, error) {
 	alterTable := &AlterTable{
 		AlterPos:   pos,
@@ -51,7 +46,7 @@ func (p *Parser) parseAlterTable(pos Pos) (*AlterTable, error) {
 			alterExpr, err = p.parseAlterTableReplacePartition(p.Pos())
 
 		default:
-			return nil, errors.New("expected token: ADD|DROP|ATTACH|DETACH|FREEZE|REMOVE|CLEAR")
+			return nil, p.errExpected(KeywordAdd, KeywordDrop, KeywordAttach, KeywordDetach, KeywordFreeze, KeywordRemove, KeywordClear)
 		}
 		if err != nil {
 			return nil, err
@@ -62,7 +57,7 @@ func (p *Parser) parseAlterTable(pos Pos) (*AlterTable, error) {
 		}
 	}
 	if len(alterTable.AlterExprs) == 0 {
-		return nil, errors.New("expected token: ADD|DROP")
+		return nil, p.errExpected(KeywordAdd, KeywordDrop)
 	}
 	alterTable.StatementEnd = alterTable.AlterExprs[len(alterTable.AlterExprs)-1].End()
 
@@ -80,7 +75,7 @@ func (p *Parser) parseAlterTableAdd(pos Pos) (AlterTableExpr, error) {
 	case p.matchKeyword(KeywordIndex):
 		return p.parseAlterTableAddIndex(pos)
 	default:
-		return nil, errors.New("expected token: COLUMN|INDEX")
+		return nil, p.errExpected(KeywordColumn, KeywordIndex)
 	}
 }
 
@@ -200,7 +195,7 @@ func (p *Parser) parseAlterTableDrop(pos Pos) (AlterTableExpr, error) {
 	case p.matchKeyword(KeywordPartition):
 		return p.parseAlterTableDropPartition(pos)
 	default:
-		return nil, errors.New("expected keyword: COLUMN|INDEX|DETACH")
+		return nil, p.errExpected(KeywordColumn, KeywordIndex, KeywordDetach)
 	}
 }
 
@@ -407,7 +402,7 @@ func (p *Parser) parseAlterTableClear(pos Pos) (AlterTableExpr, error) {
 		return p.parseAlterTableClearIndex(pos)
 
 	default:
-		return nil, errors.New("expected token: COLUMN|INDEX|PROJECTION")
+		return nil, p.errExpected(KeywordColumn, KeywordIndex, KeywordProjection)
 	}
 }
 
@@ -542,8 +537,7 @@ func (p *Parser) parseAlterTableModify(pos Pos) (AlterTableExpr, error) {
 			TTL:          ttlExpr,
 		}, nil
 	default:
-		return nil, fmt.Errorf("expected keyword: COLUMN, but got %q",
-			p.last().String)
+		return nil, p.errExpected(KeywordColumn)
 	}
 
 }
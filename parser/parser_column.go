@@ -126,15 +126,6 @@ func (p *Parser) parseCompareExpr(pos Pos) (Expr, error) {
 		return nil, err
 	}
 	switch {
-	case p.matchTokenKind("["):
-		params, err := p.parseArrayParams(pos)
-		if err != nil {
-			return nil, err
-		}
-		return &ObjectParams{
-			Object: expr,
-			Params: params,
-		}, nil
 	case p.matchTokenKind(opTypeEQ):
 	case p.matchTokenKind(opTypeLT):
 	case p.matchTokenKind(opTypeLE):
@@ -147,6 +138,8 @@ func (p *Parser) parseCompareExpr(pos Pos) (Expr, error) {
 	case p.matchKeyword(KeywordIn):
 	case p.matchKeyword(KeywordLike):
 	case p.matchKeyword(KeywordIlike):
+	case p.matchKeyword(KeywordBetween):
+		return p.parseBetweenExpr(expr, false)
 	case p.matchKeyword(KeywordGlobal):
 		_ = p.lexer.consumeToken()
 		hasGlobal = true
@@ -156,8 +149,10 @@ func (p *Parser) parseCompareExpr(pos Pos) (Expr, error) {
 		case p.matchKeyword(KeywordIn):
 		case p.matchKeyword(KeywordLike):
 		case p.matchKeyword(KeywordIlike):
+		case p.matchKeyword(KeywordBetween):
+			return p.parseBetweenExpr(expr, true)
 		default:
-			return nil, fmt.Errorf("expected IN, LIKE or ILIKE after NOT, got %s", p.lastTokenKind())
+			return nil, p.errExpected(KeywordIn, KeywordLike, KeywordIlike, KeywordBetween)
 		}
 		hasNot = true
 	default:
@@ -179,6 +174,35 @@ func (p *Parser) parseCompareExpr(pos Pos) (Expr, error) {
 	}, nil
 }
 
+// parseBetweenExpr parses the rest of expr [NOT] BETWEEN low AND high.
+// The bounds bind tighter than AND, so the AND belongs to the BETWEEN.
+func (p *Parser) parseBetweenExpr(expr Expr, not bool) (*BetweenExpr, error) {
+	betweenPos := p.Pos()
+	if err := p.consumeKeyword(KeywordBetween); err != nil {
+		return nil, err
+	}
+	low, err := p.parseAddSubExpr(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	andPos := p.Pos()
+	if err := p.consumeKeyword(KeywordAnd); err != nil {
+		return nil, err
+	}
+	high, err := p.parseAddSubExpr(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	return &BetweenExpr{
+		Expr:       expr,
+		Not:        not,
+		BetweenPos: betweenPos,
+		Low:        low,
+		AndPos:     andPos,
+		High:       high,
+	}, nil
+}
+
 func (p *Parser) parseAddSubExpr(pos Pos) (Expr, error) {
 	expr, err := p.parseMulDivModExpr(pos)
 	if err != nil {
@@ -238,7 +262,6 @@ func (p *Parser) parseMulDivModExpr(pos Pos) (Expr, error) {
 		case p.matchTokenKind(opTypeMul),
 			p.matchTokenKind(opTypeDiv),
 			p.matchTokenKind(opTypeMod),
-			p.matchTokenKind(TokenArrow),
 			p.matchTokenKind(TokenCast):
 			op := p.lastTokenKind()
 			_ = p.lexer.consumeToken()
@@ -326,7 +349,61 @@ func (p *Parser) parseUnaryExpr(pos Pos) (Expr, error) {
 
 }
 
-func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
+func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) {
+	expr, err := p.parseColumnOperand(pos)
+	if err != nil {
+		return nil, err
+	}
+	return p.parseAccessExpr(expr)
+}
+
+// parseAccessExpr parses the subscripts and tuple element accesses that
+// follow expr, like arr[1][2] or tupleElement(x, 'a').1.
+func (p *Parser) parseAccessExpr(expr Expr) (Expr, error) {
+	for {
+		switch {
+		case p.matchTokenKind("["):
+			leftBracketPos := p.Pos()
+			_ = p.lexer.consumeToken()
+			index, err := p.parseExpr(p.Pos())
+			if err != nil {
+				return nil, err
+			}
+			rightBracketPos := p.Pos()
+			if _, err := p.consumeTokenKind("]"); err != nil {
+				return nil, err
+			}
+			expr = &IndexExpr{
+				Object:          expr,
+				LeftBracketPos:  leftBracketPos,
+				Index:           index,
+				RightBracketPos: rightBracketPos,
+			}
+		case p.matchTokenKind("."):
+			dotPos := p.Pos()
+			_ = p.lexer.consumeToken()
+			var index Expr
+			var err error
+			if p.matchTokenKind(TokenInt) {
+				index, err = p.parseNumber(p.Pos())
+			} else {
+				index, err = p.parseIdent()
+			}
+			if err != nil {
+				return nil, err
+			}
+			expr = &TupleAccessExpr{
+				Tuple:  expr,
+				DotPos: dotPos,
+				Index:  index,
+			}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *Parser) parseColumnOperand(pos Pos) (Expr, error) { //nolint:funlen
 	switch {
 	case p.matchKeyword(KeywordInterval):
 		return p.parseColumnExprInterval(pos)
@@ -336,7 +413,7 @@ func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
 			return nil, err
 		}
 		if nextToken.Kind == TokenString {
-			return p.parseString(pos)
+			return p.parseTypedLiteral()
 		}
 		return p.parseIdentOrFunction(pos)
 	case p.matchKeyword(KeywordCast):
@@ -345,28 +422,121 @@ func (p *Parser) parseColumnExpr(pos Pos) (Expr, error) { //nolint:funlen
 		return p.parseColumnCaseExpr(pos)
 	case p.matchKeyword(KeywordExtract):
 		return p.parseColumnExtractExpr(pos)
+	case p.matchTokenKind(TokenInt),
+		p.matchTokenKind(TokenFloat),
+		p.matchNumberWord(): // number literal
+		return p.parseNumber(pos)
 	case p.matchTokenKind(TokenIdent):
 		return p.parseIdentOrFunction(pos)
 	case p.matchTokenKind(TokenString): // string literal
 		return p.parseString(pos)
-	case p.matchTokenKind(TokenInt),
-		p.matchTokenKind(TokenFloat): // number literal
-		return p.parseNumber(pos)
+	case p.matchTokenKind("{"): // query parameter or map literal
+		if p.matchQueryParam() {
+			return p.parseQueryParam(pos)
+		}
+		return p.parseMapLiteral(pos)
 	case p.matchTokenKind("("):
 		if peek, _ := p.lexer.peekToken(); peek != nil {
 			if peek.Kind == TokenKeyword && strings.EqualFold(peek.String, KeywordSelect) {
 				return p.parseSelectQuery(pos)
 			}
 		}
-		return p.parseFunctionParams(pos)
+		return p.parseTupleOrParams(pos)
 	case p.matchTokenKind("*"):
 		return p.parseColumnStar(pos)
 	case p.matchTokenKind("["):
 		return p.parseArrayParams(pos)
 
 	default:
-		return nil, fmt.Errorf("unexpected token kind: %s", p.lastTokenKind())
+		return nil, p.errExpected(KeywordInterval, KeywordDate, KeywordTimestamp, KeywordCast, KeywordCase, KeywordExtract,
+			string(TokenInt), string(TokenFloat), string(TokenIdent), string(TokenString), "{", "(", "*", "[")
+	}
+}
+
+// parseTypedLiteral parses a string literal with a type, like
+// DATE '2024-01-01'.
+func (p *Parser) parseTypedLiteral() (*TypedLiteral, error) {
+	lastToken := p.last()
+	_ = p.lexer.consumeToken()
+	value, err := p.parseString(p.Pos())
+	if err != nil {
+		return nil, err
 	}
+	return &TypedLiteral{
+		Type: &Ident{
+			NamePos: lastToken.Pos,
+			NameEnd: lastToken.End,
+			Name:    lastToken.String,
+		},
+		Value: value,
+	}, nil
+}
+
+// parseTupleOrParams parses a list of expressions in parentheses, which
+// is a tuple unless it holds exactly one expression, like (1).
+func (p *Parser) parseTupleOrParams(pos Pos) (Expr, error) {
+	if _, err := p.consumeTokenKind("("); err != nil {
+		return nil, err
+	}
+	items, trailingComma, err := p.parseColumnExprListWithTrailingComma(")", p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	rightParenPos := p.Pos()
+	if _, err := p.consumeTokenKind(")"); err != nil {
+		return nil, err
+	}
+	if len(items.Items) == 1 && !trailingComma {
+		return &ParamExprList{
+			LeftParenPos:  pos,
+			RightParenPos: rightParenPos,
+			Items:         items,
+		}, nil
+	}
+	return &TupleLiteral{
+		LeftParenPos:  pos,
+		RightParenPos: rightParenPos,
+		Items:         items,
+	}, nil
+}
+
+// parseMapLiteral parses a map like {'a': 1, 'b': 2}.
+func (p *Parser) parseMapLiteral(pos Pos) (*MapLiteral, error) {
+	if _, err := p.consumeTokenKind("{"); err != nil {
+		return nil, err
+	}
+	entries := make([]*MapEntry, 0)
+	for !p.matchTokenKind("}") {
+		key, err := p.parseExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		colonPos := p.Pos()
+		if _, err := p.consumeTokenKind(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpr(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &MapEntry{
+			Key:      key,
+			ColonPos: colonPos,
+			Value:    value,
+		})
+		if p.tryConsumeTokenKind(",") == nil {
+			break
+		}
+	}
+	rightBrace, err := p.consumeTokenKind("}")
+	if err != nil {
+		return nil, err
+	}
+	return &MapLiteral{
+		LeftBracePos:  pos,
+		RightBracePos: rightBrace.Pos,
+		Entries:       entries,
+	}, nil
 }
 
 func (p *Parser) tryParseTableColumnPropertyExpr(pos Pos) (Expr, error) {
@@ -427,25 +597,34 @@ func (p *Parser) parseColumnExprList(pos Pos) (*ColumnExprList, error) {
 }
 
 func (p *Parser) parseColumnExprListWithTerm(term TokenKind, pos Pos) (*ColumnExprList, error) {
+	columnExprList, _, err := p.parseColumnExprListWithTrailingComma(term, pos)
+	return columnExprList, err
+}
+
+// parseColumnExprListWithTrailingComma is like parseColumnExprListWithTerm,
+// and also reports whether the list ends with a comma, as in (1,).
+func (p *Parser) parseColumnExprListWithTrailingComma(term TokenKind, pos Pos) (*ColumnExprList, bool, error) {
 	columnExprList := &ColumnExprList{
 		ListPos: pos,
 		ListEnd: pos,
 	}
 	columnExprList.HasDistinct = p.tryConsumeKeyword(KeywordDistinct) != nil
 	columnList := make([]Expr, 0)
+	trailingComma := false
 	for !p.lexer.isEOF() || p.last() != nil {
 		if term != "" && p.matchTokenKind(term) {
 			break
 		}
 		columnExpr, err := p.parseColumnsExpr(pos)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		if columnExpr == nil {
 			break
 		}
 		columnList = append(columnList, columnExpr)
-		if p.tryConsumeTokenKind(",") == nil {
+		trailingComma = p.tryConsumeTokenKind(",") != nil
+		if !trailingComma {
 			break
 		}
 	}
@@ -453,7 +632,7 @@ func (p *Parser) parseColumnExprListWithTerm(term TokenKind, pos Pos) (*ColumnEx
 	if len(columnList) > 0 {
 		columnExprList.ListEnd = columnList[len(columnList)-1].End()
 	}
-	return columnExprList, nil
+	return columnExprList, trailingComma, nil
 }
 
 // Syntax: INTERVAL expr interval
@@ -492,46 +671,29 @@ func (p *Parser) parseFunctionExpr(_ Pos) (Expr, error) {
 	if err != nil {
 		return nil, err
 	}
-	// parse function params
-	params, err := p.parseFunctionParams(p.Pos())
-	if err != nil {
-		return nil, err
-	}
-	return &FunctionExpr{
-		Name:   name,
-		Params: params,
-	}, nil
+	return p.parseFunctionCall(name)
 }
 
-func (p *Parser) parseColumnArgList(pos Pos) (*ColumnArgList, error) {
-	if _, err := p.consumeTokenKind("("); err != nil {
+// parseFunctionCall parses the argument lists of a call to the function
+// name: the arguments, or the parameters and then the arguments of a
+// parametric aggregate function like quantile(0.95)(x).
+func (p *Parser) parseFunctionCall(name *Ident) (*FunctionExpr, error) {
+	args, err := p.parseFunctionParams(p.Pos())
+	if err != nil {
 		return nil, err
 	}
-	distinct := false
-	if p.tryConsumeKeyword(KeywordDistinct) != nil {
-		distinct = true
+	function := &FunctionExpr{
+		Name: name,
+		Args: args,
 	}
-	var items []Expr
-	for !p.lexer.isEOF() && !p.matchTokenKind(")") {
-		item, err := p.parseExpr(p.Pos())
+	if p.matchTokenKind("(") {
+		function.Parameters = args
+		function.Args, err = p.parseFunctionParams(p.Pos())
 		if err != nil {
 			return nil, err
 		}
-		items = append(items, item)
-		if p.tryConsumeTokenKind(",") == nil {
-			break
-		}
 	}
-	rightParenPos := p.Pos()
-	if _, err := p.consumeTokenKind(")"); err != nil {
-		return nil, err
-	}
-	return &ColumnArgList{
-		LeftParenPos:  pos,
-		RightParenPos: rightParenPos,
-		Distinct:      distinct,
-		Items:         items,
-	}, nil
+	return function, nil
 }
 
 func (p *Parser) parseFunctionParams(pos Pos) (*ParamExprList, error) {
@@ -546,21 +708,11 @@ func (p *Parser) parseFunctionParams(pos Pos) (*ParamExprList, error) {
 	if _, err := p.consumeTokenKind(")"); err != nil {
 		return nil, err
 	}
-	paramExprList := &ParamExprList{
+	return &ParamExprList{
 		LeftParenPos:  pos,
 		RightParenPos: rightParenPos,
 		Items:         params,
-	}
-
-	// try to parse column arg list
-	if p.matchTokenKind("(") {
-		columnArgList, err := p.parseColumnArgList(p.Pos())
-		if err != nil {
-			return nil, err
-		}
-		paramExprList.ColumnArgList = columnArgList
-	}
-	return paramExprList, nil
+	}, nil
 }
 
 func (p *Parser) parseArrayParams(pos Pos) (*ArrayParamList, error) {
@@ -583,7 +735,58 @@ func (p *Parser) parseArrayParams(pos Pos) (*ArrayParamList, error) {
 }
 
 func (p *Parser) parseColumnsExpr(pos Pos) (Expr, error) {
-	return p.parseExpr(pos)
+	expr, err := p.parseExpr(pos)
+	if err != nil || !p.matchTokenKind(TokenArrow) {
+		return expr, err
+	}
+	return p.parseLambdaExpr(expr)
+}
+
+// parseLambdaExpr parses the body of a lambda function whose parameters,
+// an identifier or a parenthesized list of identifiers, are in params.
+func (p *Parser) parseLambdaExpr(params Expr) (*LambdaExpr, error) {
+	lambda := &LambdaExpr{LambdaPos: params.Pos()}
+	var err error
+	switch params := params.(type) {
+	case *Ident:
+		lambda.Params = []*Ident{params}
+	case *ParamExprList:
+		lambda.Params, err = lambdaParams(params, params.Items.Items)
+	case *TupleLiteral:
+		lambda.Params, err = lambdaParams(params, params.Items.Items)
+	default:
+		err = fmt.Errorf("invalid lambda parameters: %s", params.String(0))
+	}
+	if err != nil {
+		return nil, err
+	}
+	lambda.ArrowPos = p.Pos()
+	if _, err := p.consumeTokenKind(TokenArrow); err != nil {
+		return nil, err
+	}
+	body, err := p.parseExpr(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	lambda.Body = body
+	return lambda, nil
+}
+
+// lambdaParams returns the identifiers in items, the parenthesized
+// parameters of a lambda function.
+func lambdaParams(params Expr, items []Expr) ([]*Ident, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("invalid lambda parameters: %s", params.String(0))
+	}
+	idents := make([]*Ident, 0, len(items))
+	for _, item := range items {
+		ident, ok := item.(*Ident)
+		if !ok || ident.Param != nil {
+			return nil, fmt.Errorf("invalid lambda parameter: %s", item.String(0))
+		}
+		idents = append(idents, ident)
+	}
+	return idents, nil
 }
 
 func (p *Parser) parseColumnCaseExpr(pos Pos) (*CaseExpr, error) {
@@ -667,7 +870,7 @@ func (p *Parser) parseColumnType(_ Pos) (Expr, error) { // nolint:funlen
 			// fixed size
 			return p.parseColumnTypeWithParams(ident, p.Pos())
 		default:
-			return nil, fmt.Errorf("unexpected token kind: %v", p.lastTokenKind())
+			return nil, p.errExpected(string(TokenIdent), string(TokenString), string(TokenInt))
 		}
 	}
 	return &ScalarTypeExpr{Name: ident}, nil
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 )
@@ -40,12 +39,18 @@ func (p *Parser) matchTokenKind(kind TokenKind) bool {
 		(kind == TokenIdent && p.lastTokenKind() == TokenKeyword)
 }
 
+// matchNumberWord reports whether the last token is one of the words inf,
+// infinity and nan, which are number literals in expressions.
+func (p *Parser) matchNumberWord() bool {
+	return p.matchTokenKind(TokenIdent) && !p.last().Unquoted && isNumberWord(p.last().String)
+}
+
 // consumeTokenKind consumes the last token if it is the given kind.
 func (p *Parser) consumeTokenKind(kind TokenKind) (*Token, error) {
 	if lastToken := p.tryConsumeTokenKind(kind); lastToken != nil {
 		return lastToken, nil
 	}
-	return nil, fmt.Errorf("expected the last token kind is: %s, but got %s", kind, p.lastTokenKind())
+	return nil, p.errExpected(string(kind))
 }
 
 func (p *Parser) tryConsumeTokenKind(kind TokenKind) *Token {
@@ -63,7 +68,7 @@ func (p *Parser) matchKeyword(keyword string) bool {
 
 func (p *Parser) consumeKeyword(keyword string) error {
 	if !p.matchKeyword(keyword) {
-		return fmt.Errorf("expected keyword: %s, but got %s", keyword, p.lastTokenKind())
+		return p.errExpected(keyword)
 	}
 	_ = p.lexer.consumeToken()
 	return nil
@@ -79,6 +84,14 @@ func (p *Parser) tryConsumeKeyword(keyword string) *Token {
 }
 
 func (p *Parser) parseIdent() (*Ident, error) {
+	if p.matchTokenKind("{") {
+		// the identifier is given by a query parameter, e.g. {db:Identifier}
+		param, err := p.parseQueryParam(p.Pos())
+		if err != nil {
+			return nil, err
+		}
+		return &Ident{NamePos: param.Pos(), NameEnd: param.End(), Param: param}, nil
+	}
 	lastToken, err := p.consumeTokenKind(TokenIdent)
 	if err != nil {
 		return nil, err
@@ -89,9 +102,54 @@ func (p *Parser) parseIdent() (*Ident, error) {
 		Name:     lastToken.String,
 		Unquoted: lastToken.Unquoted,
 	}
+	if ident.Unquoted {
+		ident.Quote = IdentQuote(p.lexer.input[lastToken.Pos-1 : lastToken.Pos])
+	}
 	return ident, nil
 }
 
+// matchQueryParam reports whether the last token, a '{', starts a query
+// parameter like {name:Type} rather than a map like {'a': 1} or {NULL: 1}:
+// the name and the type are identifiers or keywords.
+func (p *Parser) matchQueryParam() bool {
+	tokens, err := p.lexer.peekTokens(3)
+	if err != nil || len(tokens) < 3 {
+		return false
+	}
+	isName := func(token *Token) bool {
+		return token.Kind == TokenIdent || token.Kind == TokenKeyword
+	}
+	return isName(tokens[0]) && tokens[1].Kind == ":" && isName(tokens[2])
+}
+
+// parseQueryParam parses a query parameter like {name:Type}.
+func (p *Parser) parseQueryParam(pos Pos) (*QueryParam, error) {
+	if _, err := p.consumeTokenKind("{"); err != nil {
+		return nil, err
+	}
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consumeTokenKind(":"); err != nil {
+		return nil, err
+	}
+	columnType, err := p.parseColumnType(p.Pos())
+	if err != nil {
+		return nil, err
+	}
+	rightBrace, err := p.consumeTokenKind("}")
+	if err != nil {
+		return nil, err
+	}
+	return &QueryParam{
+		LeftBracePos:  pos,
+		RightBracePos: rightBrace.Pos,
+		Name:          name,
+		Type:          columnType,
+	}, nil
+}
+
 func (p *Parser) parseIdentOrStar() (*Ident, error) {
 	switch {
 	case p.matchTokenKind(TokenIdent):
@@ -105,7 +163,7 @@ func (p *Parser) parseIdentOrStar() (*Ident, error) {
 			Name:    lastToken.String,
 		}, nil
 	default:
-		return nil, fmt.Errorf("expected <ident> or *, but got %q", p.lastTokenKind())
+		return nil, p.errExpected(string(TokenIdent), "*")
 	}
 }
 
@@ -204,8 +262,17 @@ func (p *Parser) parseNumber(pos Pos) (*NumberLiteral, error) {
 		lastToken, err = p.consumeTokenKind(TokenInt)
 	case p.matchTokenKind(TokenFloat):
 		lastToken, err = p.consumeTokenKind(TokenFloat)
+	case p.matchNumberWord():
+		lastToken = p.last()
+		_ = p.lexer.consumeToken()
+		return &NumberLiteral{
+			NumPos:  pos,
+			NumEnd:  lastToken.End,
+			Literal: lastToken.String,
+			Base:    10,
+		}, nil
 	default:
-		return nil, fmt.Errorf("expected <int> or <float>, but got %q", p.lastTokenKind())
+		return nil, p.errExpected(string(TokenInt), string(TokenFloat))
 	}
 	if err != nil {
 		return nil, err
@@ -228,6 +295,8 @@ func (p *Parser) parseString(pos Pos) (*StringLiteral, error) {
 		LiteralPos: pos,
 		LiteralEnd: lastToken.End,
 		Literal:    lastToken.String,
+		Raw:        p.lexer.input[lastToken.Pos:lastToken.End],
+		Heredoc:    lastToken.Heredoc,
 	}
 	return str, nil
 }
@@ -242,7 +311,7 @@ func (p *Parser) parseLiteral(pos Pos) (Literal, error) {
 		// accept the NULL keyword
 		return &NullLiteral{NullPos: pos}, nil
 	default:
-		return nil, fmt.Errorf("expected <int>, <string> or keyword <NULL>, but got %q", p.last().Kind)
+		return nil, p.errExpected(string(TokenInt), string(TokenString), KeywordNull)
 	}
 }
 
@@ -287,40 +356,6 @@ func (p *Parser) parseFormatExpr(pos Pos) (*FormatExpr, error) {
 	}, nil
 }
 
-func (p *Parser) wrapError(err error) error {
-	if err == nil {
-		return nil
-	}
-
-	lineNo := 0
-	column := 0
-
-	for i := 0; i < int(p.Pos()); i++ {
-		if p.lexer.input[i] == '\n' {
-			lineNo++
-			column = 0
-		} else {
-			column++
-		}
-	}
-
-	lines := strings.Split(p.lexer.input, "\n")
-	var buf strings.Builder
-	buf.WriteString(fmt.Sprintf("line %d:%d %s\n", lineNo, column, err.Error()))
-	for i, line := range lines {
-		if i == lineNo {
-			buf.WriteString(line)
-			buf.WriteByte('\n')
-			for j := 0; j < column; j++ {
-				buf.WriteByte(' ')
-			}
-			buf.WriteString(strings.Repeat("^", len(p.lexer.lastToken.String)))
-			buf.WriteByte('\n')
-		}
-	}
-	return errors.New(buf.String())
-}
-
 func (p *Parser) parseRatioExpr(pos Pos) (*RatioExpr, error) {
 	numerator, err := p.parseNumber(pos)
 	if err != nil {
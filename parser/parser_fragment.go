@@ -0,0 +1,70 @@
+package parser
+
+// ParseExpr parses a single column expression, e.g. a filter such as
+// "a > 1 AND b IN (1, 2)". The whole input must form the expression.
+func ParseExpr(input string) (Expr, error) {
+	return parseFragment(input, func(p *Parser) (Expr, error) {
+		return p.parseExpr(p.Pos())
+	})
+}
+
+// ParseColumnType parses a column type such as "Nullable(String)"
+// or "Map(String, Array(UInt64))".
+func ParseColumnType(input string) (Expr, error) {
+	return parseFragment(input, func(p *Parser) (Expr, error) {
+		return p.parseColumnType(p.Pos())
+	})
+}
+
+// ParseTableIdentifier parses a table name with an optional database,
+// e.g. "db.table".
+func ParseTableIdentifier(input string) (*TableIdentifier, error) {
+	return parseFragment(input, func(p *Parser) (*TableIdentifier, error) {
+		return p.parseTableIdentifier(p.Pos())
+	})
+}
+
+// ParseOrderBy parses a list of ORDER BY keys such as "a DESC, b".
+// The ORDER BY keywords themselves are optional.
+func ParseOrderBy(input string) (*OrderByListExpr, error) {
+	return parseFragment(input, func(p *Parser) (*OrderByListExpr, error) {
+		pos := p.Pos()
+		if p.tryConsumeKeyword(KeywordOrder) != nil {
+			if err := p.consumeKeyword(KeywordBy); err != nil {
+				return nil, err
+			}
+		}
+		return p.parseOrderByExprList(pos)
+	})
+}
+
+// ParseSettings parses a list of settings such as "max_threads = 8, readonly = 1".
+// The SETTINGS keyword itself is optional.
+func ParseSettings(input string) (*SettingsExprList, error) {
+	return parseFragment(input, func(p *Parser) (*SettingsExprList, error) {
+		pos := p.Pos()
+		_ = p.tryConsumeKeyword(KeywordSettings)
+		return p.parseSettingsExprList(pos)
+	})
+}
+
+// parseFragment runs parse on input and makes sure that the whole input
+// was consumed.
+func parseFragment[T Expr](input string, parse func(p *Parser) (T, error)) (T, error) {
+	var zero T
+	p := NewParser(input)
+	if err := p.lexer.consumeToken(); err != nil {
+		return zero, p.wrapError(err)
+	}
+	node, err := parse(p)
+	if p.lexer.err != nil {
+		err = p.lexer.err
+	}
+	if err == nil && p.last() != nil {
+		err = p.errExpected(string(TokenEOF))
+	}
+	if err != nil {
+		return zero, p.wrapError(err)
+	}
+	return node, nil
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFragments(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		parse  func(string) (Expr, error)
+		input  string
+		output string
+	}{
+		{"expr", exprParser(ParseExpr), "a > 1 AND b IN (1, 2)", "a > 1 AND b IN (1, 2)"},
+		{"expr with function", exprParser(ParseExpr), "toDate(ts) = today()", "toDate(ts) = today()"},
+		{"column type", exprParser(ParseColumnType), "Nullable(String)", "Nullable(String)"},
+		{"nested column type", exprParser(ParseColumnType), "Map(String, Array(UInt64))", "Map(String,Array(UInt64))"},
+		{"table identifier", exprParser(ParseTableIdentifier), "db.events", "db.events"},
+		{"table identifier without database", exprParser(ParseTableIdentifier), "events", "events"},
+		{"order by", exprParser(ParseOrderBy), "a DESC, b", "ORDER BY a DESC, b"},
+		{"order by with keywords", exprParser(ParseOrderBy), "ORDER BY a", "ORDER BY a"},
+		{"settings", exprParser(ParseSettings), "max_threads = 8, readonly = 1", "SETTINGS max_threads=8, readonly=1"},
+		{"settings with keyword", exprParser(ParseSettings), "SETTINGS max_threads = 8", "SETTINGS max_threads=8"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			node, err := tc.parse(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.output, node.String(0))
+		})
+	}
+}
+
+func TestParseFragments_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		parse  func(string) (Expr, error)
+		input  string
+		column int
+	}{
+		{"expr with trailing tokens", exprParser(ParseExpr), "a 1", 3},
+		{"incomplete expr", exprParser(ParseExpr), "a >", 4},
+		{"column type with trailing tokens", exprParser(ParseColumnType), "String x", 8},
+		{"table identifier with three parts", exprParser(ParseTableIdentifier), "db.t.x", 5},
+		{"empty table identifier", exprParser(ParseTableIdentifier), "", 1},
+		{"order by with trailing tokens", exprParser(ParseOrderBy), "a b c", 3},
+		{"settings without value", exprParser(ParseSettings), "a", 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.parse(tc.input)
+			require.Error(t, err)
+			parseErr, ok := err.(*ParseError)
+			require.True(t, ok)
+			require.Equal(t, tc.column, parseErr.Column)
+		})
+	}
+}
+
+func exprParser[T Expr](parse func(string) (T, error)) func(string) (Expr, error) {
+	return func(input string) (Expr, error) {
+		return parse(input)
+	}
+}
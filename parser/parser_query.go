@@ -1,9 +1,6 @@
 package parser
 
-import (
-	"errors"
-	"fmt"
-)
+import "errors"
 
 func (p *Parser) tryParseWithExpr(pos Pos) (*WithExpr, error) {
 	if !p.matchKeyword(KeywordWith) {
@@ -203,7 +200,7 @@ func (p *Parser) parseJoinOp(_ Pos) (Expr, []string, error) {
 func (p *Parser) parseJoinExpr(pos Pos) (expr Expr, err error) {
 	var sampleRatio *SampleRatioExpr
 	switch {
-	case p.matchTokenKind(TokenString), p.matchTokenKind(TokenIdent), p.matchTokenKind("("):
+	case p.matchTokenKind(TokenString), p.matchTokenKind(TokenIdent), p.matchTokenKind("("), p.matchTokenKind("{"):
 		expr, err = p.parseTableExpr(p.Pos())
 		if err != nil {
 			return nil, err
@@ -215,7 +212,7 @@ func (p *Parser) parseJoinExpr(pos Pos) (expr Expr, err error) {
 			return nil, err
 		}
 	default:
-		return nil, fmt.Errorf("expected table name or subquery, got %s", p.last().Kind)
+		return nil, p.errExpected(string(TokenIdent), "(")
 	}
 
 	// TODO: store global/local in AST
@@ -250,7 +247,7 @@ func (p *Parser) parseTableExpr(pos Pos) (*TableExpr, error) {
 	switch {
 	case p.matchTokenKind(TokenString):
 		expr, err = p.parseString(p.Pos())
-	case p.matchTokenKind(TokenIdent):
+	case p.matchTokenKind(TokenIdent), p.matchTokenKind("{"):
 		// table name
 		tableIdentifier, err := p.parseTableIdentifier(p.Pos())
 		if err != nil {
@@ -273,7 +270,7 @@ func (p *Parser) parseTableExpr(pos Pos) (*TableExpr, error) {
 	case p.matchTokenKind("("):
 		expr, err = p.parseSelectQuery(p.Pos())
 	default:
-		return nil, errors.New("expect table name or subquery")
+		return nil, p.errExpected(string(TokenIdent), "(")
 	}
 	if err != nil {
 		return nil, err
@@ -402,7 +399,7 @@ func (p *Parser) parseGroupByExpr(pos Pos) (*GroupByExpr, error) {
 		case p.tryConsumeKeyword(KeywordTotals) != nil:
 			groupByExpr.WithTotals = true
 		default:
-			return nil, fmt.Errorf("expected CUBE, ROLLUP or TOTALS, got %s", p.lastTokenKind())
+			return nil, p.errExpected(KeywordCube, KeywordRollup, KeywordTotals)
 		}
 	}
 
@@ -528,7 +525,7 @@ func (p *Parser) parseWindowFrameExpr(pos Pos) (*WindowFrameExpr, error) {
 			direction = p.last().String
 			_ = p.lexer.consumeToken()
 		default:
-			return nil, fmt.Errorf("expected PRECEDING or FOLLOWING, got %s", p.lastTokenKind())
+			return nil, p.errExpected(KeywordPreceding, KeywordFollowing)
 		}
 		expr = &WindowFrameUnbounded{
 			UnboundedPos: unboundedPos,
@@ -548,7 +545,7 @@ func (p *Parser) parseWindowFrameExpr(pos Pos) (*WindowFrameExpr, error) {
 			unboundedEnd = p.last().End
 			_ = p.lexer.consumeToken()
 		default:
-			return nil, fmt.Errorf("expected PRECEDING or FOLLOWING, got %s", p.lastTokenKind())
+			return nil, p.errExpected(KeywordPreceding, KeywordFollowing)
 		}
 		expr = &WindowFrameNumber{
 			UnboundedEnd: unboundedEnd,
@@ -556,7 +553,7 @@ func (p *Parser) parseWindowFrameExpr(pos Pos) (*WindowFrameExpr, error) {
 			Direction:    direction,
 		}
 	default:
-		return nil, fmt.Errorf("expected BETWEEN, CURRENT, UNBOUNDED or integer, got %s", p.lastTokenKind())
+		return nil, p.errExpected(KeywordBetween, KeywordCurrent, KeywordUnbounded, string(TokenInt))
 	}
 	return &WindowFrameExpr{
 		FramePos: pos,
@@ -703,7 +700,7 @@ func (p *Parser) parseSubQuery(pos Pos) (*SubQueryExpr, error) {
 
 func (p *Parser) parseSelectQuery(_ Pos) (*SelectQuery, error) {
 	if !p.matchKeyword(KeywordSelect) && !p.matchKeyword(KeywordWith) && !p.matchTokenKind("(") {
-		return nil, fmt.Errorf("expected SELECT, WITH or (, got %s", p.lastTokenKind())
+		return nil, p.errExpected(KeywordSelect, KeywordWith, "(")
 	}
 
 	hasParen := p.tryConsumeTokenKind("(") != nil
@@ -727,7 +724,7 @@ func (p *Parser) parseSelectQuery(_ Pos) (*SelectQuery, error) {
 			}
 			selectExpr.UnionDistinct = unionDistinctExpr
 		default:
-			return nil, fmt.Errorf("expected ALL or DISTINCT, got %s", p.lastTokenKind())
+			return nil, p.errExpected(KeywordAll, KeywordDistinct)
 		}
 	case p.tryConsumeKeyword(KeywordExcept) != nil:
 		exceptExpr, err := p.parseSelectStatement(p.Pos())
@@ -989,7 +986,7 @@ func (p *Parser) parseExplainExpr(pos Pos) (*ExplainExpr, error) {
 		explainType = p.last().String
 		_ = p.lexer.consumeToken()
 	default:
-		return nil, fmt.Errorf("expected SYNTAX, PIPELINE, ESTIMATE or AST, got %s", p.lastTokenKind())
+		return nil, p.errExpected(KeywordSyntax, KeywordPipeline, KeywordEstimate, KeywordAst)
 	}
 	expr, err := p.parseSelectQuery(p.Pos())
 	if err != nil {
@@ -1,7 +1,8 @@
 package parser
 
 import (
-	"fmt"
+	"strings"
+	"unicode"
 )
 
 func (p *Parser) parseDDL(pos Pos) (DDL, error) {
@@ -30,8 +31,10 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 		case p.matchKeyword(KeywordRow):
 		case p.matchKeyword(KeywordSettings):
 		default:
-			return nil, fmt.Errorf("expected keyword: DATABASE|TABLE|VIEW|DICTIONARY|FUNCTION|ROW|QUOTA|SETTINGS, but got %q",
-				p.last().String)
+			return nil, p.errExpected(
+				KeywordDatabase, KeywordTable, KeywordView, KeywordDictionary, KeywordFunction, KeywordRow,
+				KeywordQuota, KeywordSettings,
+			)
 		}
 	case p.matchKeyword(KeywordAlter):
 		_ = p.lexer.consumeToken()
@@ -41,7 +44,7 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 		case p.matchKeyword(KeywordTable):
 			return p.parseAlterTable(pos)
 		default:
-			return nil, fmt.Errorf("expected keyword: TABLE|ROLE, but got %q", p.last().String)
+			return nil, p.errExpected(KeywordTable, KeywordRole)
 		}
 	case p.matchKeyword(KeywordDrop),
 		p.matchKeyword(KeywordDetach):
@@ -58,7 +61,7 @@ func (p *Parser) parseDDL(pos Pos) (DDL, error) {
 			p.matchKeyword(KeywordRole):
 			return p.parserDropUserOrRole(pos)
 		default:
-			return nil, fmt.Errorf("expected keyword: DATABASE|TABLE, but got %q", p.last().String)
+			return nil, p.errExpected(KeywordDatabase, KeywordTable)
 		}
 	case p.matchKeyword(KeywordTruncate):
 		return p.parseTruncateTable(pos)
@@ -175,24 +178,11 @@ func (p *Parser) parseIdentOrFunction(_ Pos) (Expr, error) {
 		return nil, err
 	}
 	switch {
-	case p.matchTokenKind("["):
-		params, err := p.parseArrayParams(p.Pos())
-		if err != nil {
-			return nil, err
-		}
-		return &ObjectParams{
-			Object: ident,
-			Params: params,
-		}, nil
 	case p.matchTokenKind("("):
-		params, err := p.parseFunctionParams(p.Pos())
+		funcExpr, err := p.parseFunctionCall(ident)
 		if err != nil {
 			return nil, err
 		}
-		funcExpr := &FunctionExpr{
-			Name:   ident,
-			Params: params,
-		}
 		if overToken := p.tryConsumeKeyword(KeywordOver); overToken != nil {
 			var overExpr Expr
 			switch {
@@ -204,7 +194,7 @@ func (p *Parser) parseIdentOrFunction(_ Pos) (Expr, error) {
 					return nil, err
 				}
 			default:
-				return nil, fmt.Errorf("expected IDENT or (, but got %q", p.lastTokenKind())
+				return nil, p.errExpected(string(TokenIdent), "(")
 			}
 
 			if err != nil {
@@ -217,41 +207,93 @@ func (p *Parser) parseIdentOrFunction(_ Pos) (Expr, error) {
 			}, nil
 		}
 		return funcExpr, nil
-	case p.tryConsumeTokenKind(".") != nil:
+	case p.matchTokenKind("."):
+		return p.parseDotIdentifiers(ident)
+	}
+	return ident, nil
+}
+
+// parseDotIdentifiers parses the rest of a chain of identifiers separated
+// by dots, like t.col, db.t.col.field, t.*, json.a.b.:Int64 or tup.1.
+func (p *Parser) parseDotIdentifiers(ident *Ident) (Expr, error) {
+	idents := []*Ident{ident}
+	var dots []Pos
+	for p.matchTokenKind(".") {
+		dotPos := p.Pos()
+		_ = p.lexer.consumeToken()
 		switch {
-		case p.matchTokenKind(TokenIdent):
-			nextIdent, err := p.parseIdent()
+		case p.matchTokenKind("*") && len(idents) == 1:
+			star, err := p.parseColumnStar(p.Pos())
 			if err != nil {
 				return nil, err
 			}
-			if p.tryConsumeTokenKind(".") != nil {
-				thirdIdent, err := p.parseIdent()
-				if err != nil {
-					return nil, err
-				}
-				return &ColumnIdentifier{
-					Database: ident,
-					Table:    nextIdent,
-					Column:   thirdIdent,
-				}, nil
+			return &NestedIdentifier{
+				Ident:    ident,
+				DotIdent: star,
+			}, nil
+		case p.matchTokenKind(":"):
+			typePos := p.Pos()
+			_ = p.lexer.consumeToken()
+			columnType, err := p.parseColumnType(p.Pos())
+			if err != nil {
+				return nil, err
 			}
-			return &ColumnIdentifier{
-				Table:  ident,
-				Column: nextIdent,
+			return &JSONPathExpr{
+				Object:  ident,
+				Path:    idents[1:],
+				TypePos: typePos,
+				Type:    columnType,
 			}, nil
-		case p.matchTokenKind("*"):
-			nextIdent, err := p.parseColumnStar(p.Pos())
+		case p.matchTokenKind(TokenInt):
+			index, err := p.parseNumber(p.Pos())
 			if err != nil {
 				return nil, err
 			}
-			return &NestedIdentifier{
-				Ident:    ident,
-				DotIdent: nextIdent,
+			return &TupleAccessExpr{
+				Tuple:  columnIdentifier(idents, dots),
+				DotPos: dotPos,
+				Index:  index,
 			}, nil
+		default:
+			nextIdent, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			idents = append(idents, nextIdent)
+			dots = append(dots, dotPos)
 		}
+	}
+	return columnIdentifier(idents, dots), nil
+}
 
+// columnIdentifier builds the column named by idents, which are separated
+// by the dots at dots. Identifiers after db.table.column access elements
+// of the column by their names.
+func columnIdentifier(idents []*Ident, dots []Pos) Expr {
+	var expr Expr
+	switch len(idents) {
+	case 1:
+		return idents[0]
+	case 2:
+		return &ColumnIdentifier{
+			Table:  idents[0],
+			Column: idents[1],
+		}
+	default:
+		expr = &ColumnIdentifier{
+			Database: idents[0],
+			Table:    idents[1],
+			Column:   idents[2],
+		}
 	}
-	return ident, nil
+	for i := 3; i < len(idents); i++ {
+		expr = &TupleAccessExpr{
+			Tuple:  expr,
+			DotPos: dots[i-1],
+			Index:  idents[i],
+		}
+	}
+	return expr
 }
 
 func (p *Parser) parseTableIdentifier(_ Pos) (*TableIdentifier, error) {
@@ -498,7 +540,7 @@ func (p *Parser) parseTableArgExpr(pos Pos) (Expr, error) {
 	case p.matchTokenKind(TokenInt), p.matchTokenKind(TokenString), p.matchKeyword("NULL"):
 		return p.parseLiteral(p.Pos())
 	default:
-		return nil, fmt.Errorf("unexpected token: %q, expected <Ident>, <literal>", p.last().String)
+		return nil, p.errExpected(string(TokenIdent), string(TokenInt), string(TokenString), KeywordNull)
 	}
 }
 
@@ -547,7 +589,7 @@ func (p *Parser) tryParseOnCluster(pos Pos) (*OnClusterExpr, error) {
 	case p.matchTokenKind(TokenString):
 		expr, err = p.parseString(p.Pos())
 	default:
-		return nil, fmt.Errorf("expected <Ident> or <Literal>, but got %q", p.last().String)
+		return nil, p.errExpected(string(TokenIdent), string(TokenString))
 	}
 	if err != nil {
 		return nil, err
@@ -706,7 +748,7 @@ func (p *Parser) parseTTLExpr(pos Pos) (*TTLExpr, error) {
 				return nil, err
 			}
 		} else {
-			return nil, fmt.Errorf("expected keyword <DISK> or <VOLUME>, but got %q", p.last().String)
+			return nil, p.errExpected(KeywordDisk, KeywordVolume)
 		}
 	}
 	return &TTLExpr{
@@ -789,7 +831,7 @@ func (p *Parser) parseSettingsExpr(pos Pos) (*SettingsExpr, error) {
 			return nil, err
 		}
 	default:
-		return nil, fmt.Errorf("unexpected token: %q, expected <number> or <string>", p.last().String)
+		return nil, p.errExpected(string(TokenInt), string(TokenFloat), string(TokenString))
 	}
 
 	return &SettingsExpr{
@@ -864,7 +906,7 @@ func (p *Parser) parseEngineExpr(pos Pos) (*EngineExpr, error) {
 			engineExpr.EngineEnd = params.End()
 		}
 	default:
-		return nil, fmt.Errorf("unexpected token: %s", p.lastTokenKind())
+		return nil, p.errExpected(KeywordNull, string(TokenIdent))
 	}
 
 	for !p.lexer.isEOF() {
@@ -953,7 +995,9 @@ func (p *Parser) parseStatement(pos Pos) (Expr, error) {
 	case p.matchKeyword(KeywordGrant):
 		expr, err = p.parseGrantPrivilege(pos)
 	default:
-		return nil, fmt.Errorf("unexpected token: %q", p.last().String)
+		return nil, p.errExpected(KeywordCreate, KeywordAttach, KeywordAlter, KeywordDrop, KeywordDetach,
+			KeywordTruncate, KeywordRename, KeywordSelect, KeywordWith, KeywordDelete, KeywordInsert, KeywordUse,
+			KeywordSet, KeywordSystem, KeywordOptimize, KeywordCheck, KeywordExplain, KeywordGrant)
 	}
 	if err != nil {
 		return nil, err
@@ -965,28 +1009,109 @@ func (p *Parser) parseStatement(pos Pos) (Expr, error) {
 
 	// Statement can be terminated by ';' or EOF
 	if p.last() != nil && !p.matchTokenKind(";") {
-		return nil, fmt.Errorf("<EOF> or ';' was expected, but got: %q", p.last().String)
+		return nil, p.errExpected(string(TokenEOF), ";")
 	}
 	return expr, nil
 }
 
 func (p *Parser) ParseStatements() ([]Expr, error) {
+	statements, _, err := p.parseStatements()
+	return statements, err
+}
+
+// parseStatements parses all statements and returns them together with
+// their ranges in the input.
+func (p *Parser) parseStatements() ([]Expr, []statementSpan, error) {
 	var statements []Expr
+	var spans []statementSpan
 	for {
 		_ = p.lexer.consumeToken()
+		if p.lexer.err != nil {
+			return nil, nil, p.wrapError(p.lexer.err)
+		}
 		if p.lexer.isEOF() {
 			break
 		}
 		if p.matchTokenKind(";") {
 			continue
 		}
-		statement, err := p.parseStatement(p.Pos())
+		pos := p.Pos()
+		statement, err := p.parseStatement(pos)
+		if p.lexer.err != nil {
+			// the statement is cut short by an unterminated string or comment
+			err = p.lexer.err
+		}
 		if err != nil {
-			return nil, p.wrapError(err)
+			return nil, nil, p.wrapError(err)
 		}
 		statements = append(statements, statement)
+		spans = append(spans, statementSpan{start: pos, end: p.Pos()})
+	}
+	attachComments(p.lexer.input, statements, spans, p.lexer.comments)
+	return statements, spans, nil
+}
+
+// ParseStatementsWithRecovery is like ParseStatements, but does not stop at the
+// first syntax error. After an error it skips to the next ';' and continues with
+// the following statement. The failed statements are returned as *BadStatement
+// in the statement list, and their errors in the order they occurred.
+func (p *Parser) ParseStatementsWithRecovery() ([]Expr, []*ParseError) {
+	var statements []Expr
+	var spans []statementSpan
+	var errs []*ParseError
+	for {
+		err := p.lexer.consumeToken()
+		if err == nil && p.last() == nil {
+			break
+		}
+		if err == nil && p.matchTokenKind(";") {
+			continue
+		}
+		pos := p.Pos()
+		var statement Expr
+		if err == nil {
+			statement, err = p.parseStatement(pos)
+		}
+		if p.lexer.err != nil {
+			err = p.lexer.err
+		}
+		if err == nil {
+			statements = append(statements, statement)
+			spans = append(spans, statementSpan{start: pos, end: p.Pos()})
+			continue
+		}
+		errs = append(errs, p.wrapError(err).(*ParseError))
+		end := p.skipStatement()
+		p.lexer.err, p.lexer.truncated = nil, false
+		text := strings.TrimRightFunc(p.lexer.input[pos:end], unicode.IsSpace)
+		statements = append(statements, &BadStatement{
+			StatementPos: pos,
+			StatementEnd: pos + Pos(len(text)),
+			Text:         text,
+		})
+		spans = append(spans, statementSpan{start: pos, end: end})
+	}
+	attachComments(p.lexer.input, statements, spans, p.lexer.comments)
+	return statements, errs
+}
+
+// skipStatement discards tokens up to the next ';' and returns its position,
+// or the length of the input if there is no ';' left.
+func (p *Parser) skipStatement() Pos {
+	for !p.matchTokenKind(";") {
+		if p.lexer.truncated {
+			// an unterminated string or comment runs to the end of input
+			p.lexer.skipN(len(p.lexer.input) - p.lexer.current)
+			p.lexer.lastToken = nil
+		}
+		if p.last() == nil && p.lexer.isEOF() {
+			return Pos(len(p.lexer.input))
+		}
+		if err := p.lexer.consumeToken(); err != nil {
+			p.lexer.skipN(1)
+		}
 	}
-	return statements, nil
+	return p.last().Pos
 }
 
 func (p *Parser) parseUseStatement(pos Pos) (*UseExpr, error) {
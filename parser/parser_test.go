@@ -110,3 +110,175 @@ func TestParser_Format(t *testing.T) {
 		}
 	}
 }
+
+func TestBetweenExpr(t *testing.T) {
+	sql := "SELECT * FROM t WHERE d NOT BETWEEN 1 AND 2 + 3 AND x = 1 OR y BETWEEN a AND b"
+	query := parseSingleStatement(t, sql).(*SelectQuery)
+
+	or := query.Where.Expr.(*BinaryExpr)
+	require.Equal(t, opTypeOr, or.Operation)
+	and := or.LeftExpr.(*BinaryExpr)
+	require.Equal(t, opTypeAnd, and.Operation)
+
+	notBetween := and.LeftExpr.(*BetweenExpr)
+	require.True(t, notBetween.Not)
+	require.Equal(t, "d", notBetween.Expr.String(0))
+	require.Equal(t, "1", notBetween.Low.String(0))
+	require.Equal(t, "2 + 3", notBetween.High.String(0))
+	require.Equal(t, "d NOT BETWEEN 1 AND 2 + 3", sql[notBetween.Pos():notBetween.End()])
+	require.Equal(t, "BETWEEN", sql[notBetween.BetweenPos:notBetween.BetweenPos+7])
+	require.Equal(t, "AND", sql[notBetween.AndPos:notBetween.AndPos+3])
+
+	between := or.RightExpr.(*BetweenExpr)
+	require.False(t, between.Not)
+	require.Equal(t, "y BETWEEN a AND b", between.String(0))
+}
+
+func TestBetweenExpr_MissingAnd(t *testing.T) {
+	_, err := NewParser("SELECT * FROM t WHERE d BETWEEN 1 OR 2").ParseStatements()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "expected AND")
+}
+
+func TestLambdaExpr(t *testing.T) {
+	sql := "SELECT arrayFilter((k, v) -> v > 0 AND k != 0, keys, vals)"
+	query := parseSingleStatement(t, sql).(*SelectQuery)
+	function := query.SelectColumns.Items[0].(*FunctionExpr)
+	lambda := function.Args.Items.Items[0].(*LambdaExpr)
+	require.Len(t, lambda.Params, 2)
+	require.Equal(t, "k", lambda.Params[0].Name)
+	require.Equal(t, "v", lambda.Params[1].Name)
+	require.Equal(t, "v > 0 AND k != 0", lambda.Body.String(0))
+	require.Equal(t, "(k, v) -> v > 0 AND k != 0", sql[lambda.Pos():lambda.End()])
+	require.Equal(t, "->", sql[lambda.ArrowPos:lambda.ArrowPos+2])
+	require.Len(t, function.Args.Items.Items, 3)
+
+	var names []string
+	Inspect(lambda, func(node Expr) bool {
+		if ident, ok := node.(*Ident); ok {
+			names = append(names, ident.Name)
+		}
+		return true
+	})
+	require.Equal(t, []string{"k", "v", "v", "k"}, names)
+
+	_, err := NewParser("SELECT arrayMap(x + 1 -> x, arr)").ParseStatements()
+	require.ErrorContains(t, err, "invalid lambda parameters: x + 1")
+}
+
+func TestTupleAccessExpr(t *testing.T) {
+	query := parseSingleStatement(t, "SELECT tup.1.2, t.col, db.t.col.field, (1, 2).1").(*SelectQuery)
+	items := query.SelectColumns.Items
+
+	access := items[0].(*TupleAccessExpr)
+	require.Equal(t, "2", access.Index.(*NumberLiteral).Literal)
+	inner := access.Tuple.(*TupleAccessExpr)
+	require.Equal(t, "tup", inner.Tuple.(*Ident).Name)
+	require.Equal(t, "1", inner.Index.(*NumberLiteral).Literal)
+
+	require.IsType(t, &ColumnIdentifier{}, items[1])
+
+	access = items[2].(*TupleAccessExpr)
+	require.Equal(t, "field", access.Index.(*Ident).Name)
+	require.Equal(t, "db.t.col", access.Tuple.(*ColumnIdentifier).String(0))
+
+	access = items[3].(*TupleAccessExpr)
+	require.IsType(t, &TupleLiteral{}, access.Tuple)
+
+	// a number after a dot that doesn't follow an expression is a float
+	number := parseNumberLiteral(t, ".5")
+	require.Equal(t, ".5", number.Literal)
+	_, err := NewParser("SELECT tup.1abc").ParseStatements()
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, "invalid tuple index", parseErr.Message)
+	require.Equal(t, 12, parseErr.Column)
+}
+
+func TestIndexExpr(t *testing.T) {
+	sql := "SELECT arr[1][2], 1 + m['key']"
+	query := parseSingleStatement(t, sql).(*SelectQuery)
+	items := query.SelectColumns.Items
+
+	index := items[0].(*IndexExpr)
+	require.Equal(t, "2", index.Index.(*NumberLiteral).Literal)
+	require.Equal(t, "arr[1]", index.Object.(*IndexExpr).String(0))
+	require.Equal(t, "arr[1][2]", sql[index.Pos():index.End()])
+
+	binary := items[1].(*BinaryExpr)
+	index = binary.RightExpr.(*IndexExpr)
+	require.Equal(t, "m", index.Object.(*Ident).Name)
+}
+
+func TestJSONPathExpr(t *testing.T) {
+	sql := "SELECT json.a.b.:Array(Int64)"
+	query := parseSingleStatement(t, sql).(*SelectQuery)
+	path := query.SelectColumns.Items[0].(*JSONPathExpr)
+	require.Equal(t, "json", path.Object.(*Ident).Name)
+	require.Len(t, path.Path, 2)
+	require.Equal(t, "b", path.Path[1].Name)
+	require.Equal(t, ":", sql[path.TypePos:path.TypePos+1])
+	require.Equal(t, "json.a.b.:Array(Int64)", path.String(0))
+}
+
+func TestTypedLiteral(t *testing.T) {
+	sql := "SELECT date '2024-01-01', TIMESTAMP '2024-01-01 00:00:00', DATE(ts)"
+	query := parseSingleStatement(t, sql).(*SelectQuery)
+	items := query.SelectColumns.Items
+
+	date := items[0].(*TypedLiteral)
+	require.Equal(t, "date", date.Type.Name)
+	require.Equal(t, "2024-01-01", date.Value.Literal)
+	require.Equal(t, "date", sql[date.Pos():date.Type.End()])
+
+	timestamp := items[1].(*TypedLiteral)
+	require.Equal(t, "TIMESTAMP '2024-01-01 00:00:00'", timestamp.String(0))
+
+	require.IsType(t, &FunctionExpr{}, items[2])
+}
+
+func TestTupleLiteral(t *testing.T) {
+	sql := "SELECT (1, 'x'), (), (1), (1,)"
+	query := parseSingleStatement(t, sql).(*SelectQuery)
+	items := query.SelectColumns.Items
+
+	tuple := items[0].(*TupleLiteral)
+	require.Len(t, tuple.Items.Items, 2)
+	require.Equal(t, "(1, 'x')", sql[tuple.Pos():tuple.End()])
+	require.Empty(t, items[1].(*TupleLiteral).Items.Items)
+	require.IsType(t, &ParamExprList{}, items[2])
+	single := items[3].(*TupleLiteral)
+	require.Len(t, single.Items.Items, 1)
+	require.Equal(t, "(1,)", sql[single.Pos():single.End()])
+	require.Equal(t, "(1,)", single.String(0))
+
+	_, err := NewParser("SELECT arrayMap(() -> 1, arr)").ParseStatements()
+	require.ErrorContains(t, err, "invalid lambda parameters: ()")
+}
+
+func TestMapLiteral(t *testing.T) {
+	sql := "SELECT {'a': 1, 'b': 2 + 3}, {}, {name:String}"
+	query := parseSingleStatement(t, sql).(*SelectQuery)
+	items := query.SelectColumns.Items
+
+	m := items[0].(*MapLiteral)
+	require.Len(t, m.Entries, 2)
+	require.Equal(t, "a", m.Entries[0].Key.(*StringLiteral).Literal)
+	require.Equal(t, "2 + 3", m.Entries[1].Value.String(0))
+	require.Equal(t, ":", sql[m.Entries[0].ColonPos:m.Entries[0].ColonPos+1])
+	require.Equal(t, "{'a': 1, 'b': 2 + 3}", sql[m.Pos():m.End()])
+	require.Empty(t, items[1].(*MapLiteral).Entries)
+	require.IsType(t, &QueryParam{}, items[2])
+
+	// a keyword or identifier key is a query parameter name only if a
+	// type follows the colon
+	query = parseSingleStatement(t, "SELECT {NULL: 1}, {true: 'x'}, {key: 1 + 2}, {from:DateTime}").(*SelectQuery)
+	items = query.SelectColumns.Items
+	require.Equal(t, "NULL", items[0].(*MapLiteral).Entries[0].Key.String(0))
+	require.Equal(t, "{true: 'x'}", items[1].String(0))
+	require.Equal(t, "key", items[2].(*MapLiteral).Entries[0].Key.String(0))
+	require.Equal(t, "from", items[3].(*QueryParam).Name.Name)
+
+	_, err := NewParser("SELECT {'a' 1}").ParseStatements()
+	require.Error(t, err)
+}
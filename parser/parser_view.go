@@ -1,7 +1,5 @@
 package parser
 
-import "fmt"
-
 func (p *Parser) parseCreateMaterializedView(pos Pos) (*CreateMaterializedView, error) {
 	if err := p.consumeKeyword(KeywordMaterialized); err != nil {
 		return nil, err
@@ -64,7 +62,7 @@ func (p *Parser) parseCreateMaterializedView(pos Pos) (*CreateMaterializedView,
 			createMaterializedView.StatementEnd = populate.End
 		}
 	default:
-		return nil, fmt.Errorf("unexpected token: %q, expected TO or ENGINE", p.lastTokenKind())
+		return nil, p.errExpected(KeywordTo, KeywordEngine)
 	}
 	if p.matchKeyword(KeywordAs) {
 		subQuery, err := p.parseSubQuery(p.Pos())
@@ -201,11 +199,12 @@ func (p *Parser) tryParseWithTimeout(pos Pos) (*WithTimeoutExpr, error) {
 	if p.tryConsumeKeyword(KeywordWith) == nil {
 		return nil, nil // nolint
 	}
-	if err := p.consumeKeyword(KeywordTimeout); err != nil {
-		return nil, err
+	timeoutToken := p.tryConsumeKeyword(KeywordTimeout)
+	if timeoutToken == nil {
+		return nil, p.errExpected(KeywordTimeout)
 	}
 
-	withTimeoutExpr := &WithTimeoutExpr{WithTimeoutPos: pos}
+	withTimeoutExpr := &WithTimeoutExpr{WithTimeoutPos: pos, TimeoutEnd: timeoutToken.End}
 
 	if p.matchTokenKind(TokenInt) {
 		decimalNumber, err := p.parseDecimal(p.Pos())
@@ -0,0 +1,436 @@
+package parser
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// File is a SQL script parsed in lossless mode: it keeps the source text
+// and a snapshot of the statements as parsed, so that Print can reproduce
+// the parts of the tree that were not modified byte for byte, including
+// their spacing and comments.
+type File struct {
+	Source     string
+	Statements []Expr
+
+	// tokens are the non-trivia tokens of Source, with exact positions.
+	tokens []Token
+	// origins maps the nodes of Statements to their snapshot.
+	origins map[Expr]Expr
+	// spans are the source ranges of the snapshot nodes.
+	spans map[Expr]sourceSpan
+	// statements is the snapshot of Statements.
+	statements []Expr
+	// extents are the source ranges of the snapshot statements together
+	// with their comments and terminating ';'.
+	extents []sourceSpan
+}
+
+type sourceSpan struct {
+	start, end int
+}
+
+// ParseFile parses sql in lossless mode. The statements of the returned
+// File can be modified in place, e.g. with Apply, and then printed with
+// File.Print or File.String, which only re-render the modified nodes.
+func ParseFile(sql string) (*File, error) {
+	statements, statementSpans, err := NewParser(sql).parseStatements()
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{
+		Source:     sql,
+		Statements: statements,
+		origins:    make(map[Expr]Expr),
+		spans:      make(map[Expr]sourceSpan),
+	}
+	f.tokens = significantTokens(tokens)
+	for i, statement := range statements {
+		snapshot := Clone(statement)
+		f.statements = append(f.statements, snapshot)
+		var nodes []Expr
+		Inspect(statement, func(node Expr) bool {
+			nodes = append(nodes, node)
+			return true
+		})
+		j := 0
+		Inspect(snapshot, func(node Expr) bool {
+			// Inspect calls the function with nil after the children
+			if node != nil {
+				f.origins[nodes[j]] = node
+				if span, ok := f.snap(node.Pos(), node.End()); ok {
+					f.spans[node] = span
+				}
+			}
+			j++
+			return true
+		})
+		// the parser knows where statements end even if End doesn't
+		if span, ok := f.snap(statementSpans[i].start, statementSpans[i].end); ok {
+			f.spans[snapshot] = span
+			f.extents = append(f.extents, f.extent(snapshot, span))
+		}
+	}
+	return f, nil
+}
+
+// extent returns the range of statement including its comments and the
+// ';' that terminates it, which stay with the statement when statements
+// are added or removed around it.
+func (f *File) extent(statement Expr, span sourceSpan) sourceSpan {
+	extent := span
+	i := sort.Search(len(f.tokens), func(i int) bool { return int(f.tokens[i].Pos) >= span.end })
+	if i < len(f.tokens) && f.tokens[i].Kind == ";" {
+		extent.end = int(f.tokens[i].End)
+	}
+	if trivia := attachedTrivia(statement); trivia != nil {
+		for _, comment := range trivia.Leading {
+			if start := int(comment.CommentPos); start < extent.start {
+				extent.start = start
+			}
+		}
+		for _, comment := range trivia.Trailing {
+			if end := int(comment.CommentEnd); end > extent.end {
+				extent.end = end
+			}
+		}
+	}
+	return extent
+}
+
+// snap returns the source range of the tokens from pos to end.
+func (f *File) snap(pos, end Pos) (sourceSpan, bool) {
+	return snapSpan(f.tokens, pos, end)
+}
+
+// snapSpan extends the range from pos to end to the tokens it overlaps,
+// including the quotes of strings and quoted identifiers at either end.
+func snapSpan(tokens []Token, pos, end Pos) (sourceSpan, bool) {
+	i := sort.Search(len(tokens), func(i int) bool { return tokens[i].End > pos })
+	j := sort.Search(len(tokens), func(j int) bool { return tokens[j].Pos >= end }) - 1
+	if i >= len(tokens) || j < i {
+		return sourceSpan{}, false
+	}
+	return sourceSpan{start: int(tokens[i].Pos), end: int(tokens[j].End)}, true
+}
+
+// significantTokens returns tokens without whitespace and comments.
+func significantTokens(tokens []Token) []Token {
+	var significant []Token
+	for _, token := range tokens {
+		if token.Kind != TokenWhitespace && token.Kind != TokenComment {
+			significant = append(significant, token)
+		}
+	}
+	return significant
+}
+
+// String returns the whole script, with the statements printed by Print.
+// Statements added to or removed from Statements are inserted or removed
+// together with a separator like the ones in the source.
+func (f *File) String() string {
+	if len(f.statements) == 0 {
+		var builder strings.Builder
+		builder.WriteString(f.Source)
+		for _, statement := range f.Statements {
+			if builder.Len() > 0 && !strings.HasSuffix(builder.String(), "\n") {
+				builder.WriteByte('\n')
+			}
+			builder.WriteString(render(statement))
+			builder.WriteString(";\n")
+		}
+		return builder.String()
+	}
+	region, text, ok := f.printStatements()
+	if !ok {
+		var builder strings.Builder
+		for _, statement := range f.Statements {
+			builder.WriteString(render(statement))
+			builder.WriteString(";\n")
+		}
+		return builder.String()
+	}
+	return f.Source[:region.start] + text + f.Source[region.end:]
+}
+
+// printStatements prints f.Statements like printList, but each statement
+// that is still in the list keeps its comments and its ';' from the source.
+// New statements are terminated with ';' and put on their own line.
+func (f *File) printStatements() (sourceSpan, string, bool) {
+	if len(f.extents) != len(f.statements) {
+		return sourceSpan{}, "", false
+	}
+	indexes := make(map[Expr]int, len(f.statements))
+	for i, statement := range f.statements {
+		indexes[statement] = i
+	}
+	region := sourceSpan{start: f.extents[0].start, end: f.extents[len(f.extents)-1].end}
+
+	var builder strings.Builder
+	last := -1
+	for i, statement := range f.Statements {
+		index, known := indexes[f.origins[statement]]
+		if known && index <= last {
+			// the statements were reordered
+			return sourceSpan{}, "", false
+		}
+		isLast := i == len(f.Statements)-1
+		if !known {
+			builder.WriteString(render(statement))
+			builder.WriteByte(';')
+		} else {
+			span, extent := f.spans[f.statements[index]], f.extents[index]
+			builder.WriteString(f.Source[extent.start:span.start])
+			builder.WriteString(f.Print(statement))
+			trailer := f.Source[span.end:extent.end]
+			if !isLast && !strings.HasPrefix(strings.TrimSpace(trailer), ";") {
+				builder.WriteByte(';')
+			}
+			builder.WriteString(trailer)
+			last = index
+		}
+		if isLast {
+			break
+		}
+		next, nextKnown := indexes[f.origins[f.Statements[i+1]]]
+		if known && nextKnown && next == index+1 {
+			builder.WriteString(f.Source[f.extents[index].end:f.extents[next].start])
+		} else {
+			builder.WriteByte('\n')
+		}
+	}
+	return region, builder.String(), true
+}
+
+// Print returns the SQL text of node, which is either a node of
+// f.Statements or a new node. Nodes that are unchanged since ParseFile
+// are copied from the source. Modified nodes keep the source text between
+// their children, and only the changed children are re-rendered; a node
+// is rendered with String when its own fields changed, or when a child is
+// added where the source has none.
+func (f *File) Print(node Expr) string {
+	if isNilExpr(node) {
+		return ""
+	}
+	origin, ok := f.origins[node]
+	if !ok {
+		return render(node)
+	}
+	span, ok := f.spans[origin]
+	if !ok {
+		return render(node)
+	}
+	if Equal(node, origin, EqualOptions{}) {
+		return f.Source[span.start:span.end]
+	}
+	if text, ok := f.splice(node, origin, span); ok {
+		return text
+	}
+	return render(node)
+}
+
+type replacement struct {
+	sourceSpan
+	text string
+}
+
+// splice prints a modified node from the source text of origin, replacing
+// the spans of its children.
+func (f *File) splice(node, origin Expr, span sourceSpan) (string, bool) {
+	value := reflect.ValueOf(node).Elem()
+	originValue := reflect.ValueOf(origin).Elem()
+	if value.Kind() != reflect.Struct {
+		return "", false
+	}
+	var replacements []replacement
+	for i := 0; i < value.NumField(); i++ {
+		field, originField := value.Field(i), originValue.Field(i)
+		switch {
+		case field.Type().Implements(exprType):
+			child, _ := field.Interface().(Expr)
+			originChild, _ := originField.Interface().(Expr)
+			if isNilExpr(child) && isNilExpr(originChild) {
+				continue
+			}
+			if isNilExpr(originChild) {
+				return "", false
+			}
+			childSpan, ok := f.spans[originChild]
+			if !ok {
+				return "", false
+			}
+			replacements = append(replacements, replacement{childSpan, f.Print(child)})
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Struct &&
+			field.Type().Elem().Implements(exprType):
+			items := make([]Expr, field.Len())
+			for j := range items {
+				items[j], _ = field.Index(j).Interface().(Expr)
+			}
+			originItems := make([]Expr, originField.Len())
+			for j := range originItems {
+				originItems[j], _ = originField.Index(j).Interface().(Expr)
+			}
+			if len(originItems) == 0 {
+				if len(items) == 0 {
+					continue
+				}
+				return "", false
+			}
+			region, text, ok := f.printList(items, originItems, ", ")
+			if !ok {
+				return "", false
+			}
+			replacements = append(replacements, replacement{region, text})
+		default:
+			if !equalValue(field, originField, EqualOptions{IgnorePos: true}) {
+				return "", false
+			}
+		}
+	}
+
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start < replacements[j].start
+	})
+	var builder strings.Builder
+	offset := span.start
+	for _, r := range replacements {
+		if r.start < offset || r.end > span.end {
+			// the children overlap or are outside of the node
+			return "", false
+		}
+		builder.WriteString(f.Source[offset:r.start])
+		builder.WriteString(r.text)
+		offset = r.end
+	}
+	builder.WriteString(f.Source[offset:span.end])
+	return builder.String(), true
+}
+
+// printList prints the items of a list whose snapshot is originItems, and
+// returns the source region it replaces. The items that are still in the
+// list keep the separator that follows them in the source, and new items
+// are separated like the first two items in the source, or with
+// defaultSeparator if the source has only one item.
+func (f *File) printList(items, originItems []Expr, defaultSeparator string) (sourceSpan, string, bool) {
+	indexes := make(map[Expr]int, len(originItems))
+	spans := make([]sourceSpan, len(originItems))
+	for i, item := range originItems {
+		span, ok := f.spans[item]
+		if !ok || i > 0 && span.start < spans[i-1].end {
+			return sourceSpan{}, "", false
+		}
+		indexes[item] = i
+		spans[i] = span
+	}
+	region := sourceSpan{start: spans[0].start, end: spans[len(spans)-1].end}
+	separator := defaultSeparator
+	if len(spans) > 1 {
+		separator = plainSeparator(f.Source[spans[0].end:spans[1].start])
+	}
+
+	var builder strings.Builder
+	last := -1
+	for i, item := range items {
+		index, known := indexes[f.origins[item]]
+		if known && index <= last {
+			// the list was reordered
+			return sourceSpan{}, "", false
+		}
+		builder.WriteString(f.Print(item))
+		if i == len(items)-1 {
+			break
+		}
+		if known && index < len(spans)-1 {
+			builder.WriteString(f.Source[spans[index].end:spans[index+1].start])
+		} else {
+			builder.WriteString(separator)
+		}
+		if known {
+			last = index
+		}
+	}
+	return region, builder.String(), true
+}
+
+// plainSeparator returns separator without its comments and with only the
+// whitespace following its last token, so that it can be repeated.
+func plainSeparator(separator string) string {
+	tokens, err := Tokenize(separator)
+	if err != nil {
+		return separator
+	}
+	var builder strings.Builder
+	space := ""
+	for _, token := range tokens {
+		switch token.Kind {
+		case TokenComment:
+		case TokenWhitespace:
+			space = token.Raw
+		default:
+			builder.WriteString(token.Raw)
+			space = ""
+		}
+	}
+	if builder.Len() > 0 && space == "" {
+		space = " "
+	}
+	return builder.String() + space
+}
+
+// render formats a node with String, without the comments attached to it
+// or outside of its range in nested nodes: those are still in the source
+// text around the node.
+func render(node Expr) string {
+	pos, end := node.Pos(), node.End()
+	outside := func(comment Comment) bool {
+		return comment.CommentPos < pos || comment.CommentEnd > end
+	}
+	found := false
+	Inspect(node, func(n Expr) bool {
+		if trivia := attachedTrivia(n); trivia != nil && !found {
+			found = n == node || anyComment(trivia.Leading, outside) || anyComment(trivia.Trailing, outside)
+		}
+		return !found
+	})
+	if !found {
+		return node.String(0)
+	}
+	node = Clone(node)
+	Inspect(node, func(n Expr) bool {
+		if trivia := attachedTrivia(n); trivia != nil {
+			kept := &Trivia{}
+			if n != node {
+				kept.Leading = keepComments(trivia.Leading, outside)
+				kept.Trailing = keepComments(trivia.Trailing, outside)
+			}
+			triviaField(n).Set(reflect.ValueOf(kept))
+		}
+		return true
+	})
+	return node.String(0)
+}
+
+func anyComment(comments []Comment, match func(Comment) bool) bool {
+	for _, comment := range comments {
+		if match(comment) {
+			return true
+		}
+	}
+	return false
+}
+
+// keepComments returns the comments that don't match drop.
+func keepComments(comments []Comment, drop func(Comment) bool) []Comment {
+	var kept []Comment
+	for _, comment := range comments {
+		if !drop(comment) {
+			kept = append(kept, comment)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_Unmodified(t *testing.T) {
+	for _, dir := range []string{"./testdata/dml", "./testdata/ddl", "./testdata/query", "./testdata/basic"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+		require.NoError(t, err)
+		for _, file := range files {
+			t.Run(file, func(t *testing.T) {
+				fileBytes, err := os.ReadFile(file)
+				require.NoError(t, err)
+				f, err := ParseFile(string(fileBytes))
+				require.NoError(t, err)
+				require.Equal(t, string(fileBytes), f.String())
+			})
+		}
+	}
+}
+
+func TestFile_Print(t *testing.T) {
+	t.Run("rename table", func(t *testing.T) {
+		f, err := ParseFile("-- report\nSELECT  a,b   FROM db.t1 -- source\nWHERE a>1;\n")
+		require.NoError(t, err)
+		Inspect(f.Statements[0], func(node Expr) bool {
+			if table, ok := node.(*TableIdentifier); ok {
+				table.Table.Name = "t2"
+			}
+			return true
+		})
+		require.Equal(t, "-- report\nSELECT  a,b   FROM db.t2 -- source\nWHERE a>1;\n", f.String())
+	})
+
+	t.Run("add column", func(t *testing.T) {
+		sql := `CREATE TABLE t1
+(
+    id UInt64, -- key
+    name String
+) ENGINE = MergeTree ORDER BY id;
+`
+		f, err := ParseFile(sql)
+		require.NoError(t, err)
+		schema := f.Statements[0].(*CreateTable).TableSchema
+		schema.Columns = append(schema.Columns, &Column{
+			Name: &Ident{Name: "created_at"},
+			Type: &ScalarTypeExpr{Name: &Ident{Name: "DateTime"}},
+		})
+		require.Equal(t, `CREATE TABLE t1
+(
+    id UInt64, -- key
+    name String,
+    created_at DateTime
+) ENGINE = MergeTree ORDER BY id;
+`, f.String())
+	})
+
+	t.Run("replace expression", func(t *testing.T) {
+		f, err := ParseFile("SELECT a FROM t1 WHERE a  >  1 AND b = 2")
+		require.NoError(t, err)
+		Apply(f.Statements[0], func(c *Cursor) bool {
+			if literal, ok := c.Node().(*NumberLiteral); ok && literal.Literal == "2" {
+				c.Replace(&StringLiteral{Literal: "x"})
+			}
+			return true
+		}, nil)
+		require.Equal(t, "SELECT a FROM t1 WHERE a  >  1 AND b = 'x'", f.String())
+	})
+
+	t.Run("remove list item", func(t *testing.T) {
+		f, err := ParseFile("SELECT a,  b,  c FROM t1")
+		require.NoError(t, err)
+		columns := f.Statements[0].(*SelectQuery).SelectColumns
+		columns.Items = columns.Items[1:]
+		require.Equal(t, "SELECT b,  c FROM t1", f.String())
+		require.Equal(t, "b,  c", f.Print(columns))
+	})
+
+	t.Run("add and remove statements", func(t *testing.T) {
+		f, err := ParseFile("USE db1;\n-- settings\nSET a = 1;\n")
+		require.NoError(t, err)
+		f.Statements = append(f.Statements[1:], &UseExpr{Database: &Ident{Name: "db2"}})
+		require.Equal(t, "-- settings\nSET a = 1;\nUSE db2;\n", f.String())
+	})
+
+	t.Run("statements keep their comments", func(t *testing.T) {
+		f, err := ParseFile("USE db1; -- first\nSET a = 1 -- last\n")
+		require.NoError(t, err)
+		f.Statements = append(f.Statements, &UseExpr{Database: &Ident{Name: "db2"}})
+		require.Equal(t, "USE db1; -- first\nSET a = 1; -- last\nUSE db2;\n", f.String())
+
+		f.Statements = []Expr{&UseExpr{Database: &Ident{Name: "db0"}}, f.Statements[1]}
+		require.Equal(t, "USE db0;\nSET a = 1 -- last\n", f.String())
+	})
+
+	t.Run("changed field", func(t *testing.T) {
+		f, err := ParseFile("DROP TABLE   t1;")
+		require.NoError(t, err)
+		f.Statements[0].(*DropStmt).IfExists = true
+		require.Equal(t, "DROP TABLE IF EXISTS t1;", f.String())
+	})
+
+	t.Run("new node", func(t *testing.T) {
+		f, err := ParseFile("SELECT 1")
+		require.NoError(t, err)
+		require.Equal(t, "a + 1", f.Print(&BinaryExpr{
+			LeftExpr:  &Ident{Name: "a"},
+			Operation: "+",
+			RightExpr: &NumberLiteral{Literal: "1"},
+		}))
+	})
+}
+
+func TestFile_OptionalParts(t *testing.T) {
+	for _, sql := range []string{
+		"CHECK TABLE t1",
+		"INSERT INTO t1 (a, b) FORMAT CSV",
+		"ALTER ROLE r1 SETTINGS max_threads",
+		"CREATE LIVE VIEW v1 WITH TIMEOUT AS SELECT 1",
+	} {
+		t.Run(sql, func(t *testing.T) {
+			f, err := ParseFile(sql)
+			require.NoError(t, err)
+			Inspect(f.Statements[0], func(node Expr) bool {
+				if node != nil {
+					require.NotPanics(t, func() { node.End() }, "%T", node)
+				}
+				return true
+			})
+			require.Equal(t, sql, f.String())
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package parser
+
+// QueryParams returns the query parameters used in the tree rooted at
+// node, e.g. {id:UInt64}, in source order. A parameter used several times
+// is returned once per use.
+func QueryParams(node Expr) []*QueryParam {
+	var params []*QueryParam
+	Inspect(node, func(node Expr) bool {
+		if param, ok := node.(*QueryParam); ok {
+			params = append(params, param)
+			return false
+		}
+		return true
+	})
+	return params
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryParams(t *testing.T) {
+	stmt := parseSingleStatement(t,
+		"SELECT {col:Identifier} FROM {db:Identifier}.{table:Identifier} "+
+			"WHERE id = {id:UInt64} AND ts > {from:DateTime} AND tags = {tags:Array(Nullable(String))} AND id != {id:UInt64}")
+
+	var params []string
+	for _, param := range QueryParams(stmt) {
+		params = append(params, param.Name.Name+" "+param.Type.String(0))
+	}
+	require.Equal(t, []string{
+		"col Identifier",
+		"db Identifier",
+		"table Identifier",
+		"id UInt64",
+		"from DateTime",
+		"tags Array(Nullable(String))",
+		"id UInt64",
+	}, params)
+
+	var table *TableIdentifier
+	Inspect(stmt, func(node Expr) bool {
+		if t, ok := node.(*TableIdentifier); ok {
+			table = t
+		}
+		return true
+	})
+	require.NotNil(t, table.Database.Param)
+	require.Equal(t, "{db:Identifier}.{table:Identifier}", table.String(0))
+
+	reparsed := parseSingleStatement(t, stmt.String(0))
+	require.True(t, Equal(stmt, reparsed, EqualOptions{IgnorePos: true}))
+}
+
+func TestQueryParams_Statements(t *testing.T) {
+	for _, sql := range []string{
+		"INSERT INTO {table:Identifier} (a, b) VALUES ({a:String}, {b:Int32})",
+		"ALTER TABLE {table:Identifier} DROP COLUMN {column:Identifier}",
+		"DROP TABLE IF EXISTS {db:Identifier}.events",
+		"SELECT {x:UInt8} + 1 AS y",
+	} {
+		t.Run(sql, func(t *testing.T) {
+			stmt := parseSingleStatement(t, sql)
+			require.NotEmpty(t, QueryParams(stmt))
+			for _, param := range QueryParams(stmt) {
+				require.Equal(t, param.String(0), sql[param.Pos():param.End()])
+			}
+		})
+	}
+
+	_, err := NewParser("SELECT {id UInt64}").ParseStatements()
+	require.Error(t, err)
+}
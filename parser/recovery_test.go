@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatementsWithRecovery(t *testing.T) {
+	sql := `CREATE TABLE t1 (id UInt64) ENGINE = MergeTree() ORDER BY id;
+ALTER TABLE t1 FOO COLUMN a;
+SELECT id FROM t1;
+SELECT FROM WHERE ;
+DROP TABLE t1`
+	stmts, errs := NewParser(sql).ParseStatementsWithRecovery()
+	require.Len(t, stmts, 5)
+	require.IsType(t, &CreateTable{}, stmts[0])
+	require.IsType(t, &SelectQuery{}, stmts[2])
+	require.IsType(t, &DropStmt{}, stmts[4])
+
+	require.IsType(t, &BadStatement{}, stmts[1])
+	require.Equal(t, "ALTER TABLE t1 FOO COLUMN a", stmts[1].String(0))
+	require.Equal(t, "ALTER TABLE t1 FOO COLUMN a", sql[stmts[1].Pos():stmts[1].End()])
+	require.IsType(t, &BadStatement{}, stmts[3])
+	require.Equal(t, "SELECT FROM WHERE", stmts[3].String(0))
+
+	require.Len(t, errs, 2)
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, "FOO", errs[0].Token.String)
+	require.Equal(t, 4, errs[1].Line)
+}
+
+func TestParseStatementsWithRecovery_NoErrors(t *testing.T) {
+	sql := "SELECT 1; SELECT 2;"
+	expected, err := NewParser(sql).ParseStatements()
+	require.NoError(t, err)
+	stmts, errs := NewParser(sql).ParseStatementsWithRecovery()
+	require.Empty(t, errs)
+	require.Len(t, stmts, len(expected))
+	for i := range stmts {
+		require.True(t, Equal(expected[i], stmts[i], EqualOptions{}))
+	}
+}
+
+func TestParseStatementsWithRecovery_UnterminatedString(t *testing.T) {
+	stmts, errs := NewParser("SELECT 'abc").ParseStatementsWithRecovery()
+	require.Len(t, errs, 1)
+	require.Len(t, stmts, 1)
+	require.Equal(t, "SELECT 'abc", stmts[0].String(0))
+}
+
+func TestParseStatementsWithRecovery_UnterminatedComment(t *testing.T) {
+	stmts, errs := NewParser("SELECT 1; /* a; SELECT 2;").ParseStatementsWithRecovery()
+	require.Len(t, errs, 1)
+	require.Equal(t, "unterminated comment", errs[0].Message)
+	require.Equal(t, Pos(10), errs[0].Pos)
+	require.Len(t, stmts, 2)
+	require.IsType(t, &SelectQuery{}, stmts[0])
+	require.Equal(t, "/* a; SELECT 2;", stmts[1].String(0))
+}
+
+func TestParseStatementsWithRecovery_InvalidNumber(t *testing.T) {
+	stmts, errs := NewParser("SELECT 0b102; SELECT 2;").ParseStatementsWithRecovery()
+	require.Len(t, errs, 1)
+	require.Equal(t, "invalid number", errs[0].Message)
+	require.Equal(t, Pos(7), errs[0].Pos)
+	require.Len(t, stmts, 2)
+	require.Equal(t, "SELECT 0b102", stmts[0].String(0))
+	require.IsType(t, &SelectQuery{}, stmts[1])
+}
+
+func TestParseStatementsWithRecovery_InvalidEscape(t *testing.T) {
+	stmts, errs := NewParser(`SELECT 'a\x4'; SELECT 2;`).ParseStatementsWithRecovery()
+	require.Len(t, errs, 1)
+	require.Equal(t, "invalid hexadecimal escape sequence", errs[0].Message)
+	require.Equal(t, Pos(9), errs[0].Pos)
+	require.Len(t, stmts, 2)
+	require.Equal(t, `SELECT 'a\x4'`, stmts[0].String(0))
+	require.IsType(t, &BadStatement{}, stmts[0])
+	require.IsType(t, &SelectQuery{}, stmts[1])
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// An ApplyFunc is invoked by Apply for each non-nil node n, before
+// and/or after the node's children, using a Cursor describing the
+// current node and providing operations on it. The only nil node post
+// can see is one that pre deleted by calling Replace(nil).
+//
+// The return value of ApplyFunc controls the syntax tree traversal.
+// See Apply for details.
+type ApplyFunc func(*Cursor) bool
+
+// Apply traverses a syntax tree recursively, starting with root,
+// and calling pre and post for each node as described below.
+// Apply returns the syntax tree, possibly modified.
+//
+// If pre is not nil, it is called for each node before the node's
+// children are traversed (pre-order). If pre returns false, no
+// children are traversed, and post is not called for that node.
+//
+// If post is not nil, and a prior call of pre didn't return false,
+// post is called for each node after its children are traversed
+// (post-order). If post returns false, traversal is terminated and
+// Apply returns immediately.
+//
+// Only non-nil children are traversed, and a nil root is returned
+// without calling pre or post. If a node is replaced by pre, the
+// children of the new node are traversed instead.
+func Apply(root Expr, pre, post ApplyFunc) (result Expr) {
+	if isNilExpr(root) {
+		return root
+	}
+	defer func() {
+		if r := recover(); r != nil && r != errAbortApply {
+			panic(r)
+		}
+	}()
+	a := &application{pre: pre, post: post}
+	result = root
+	a.apply(nil, "", -1, root, &result)
+	return result
+}
+
+var errAbortApply = new(int)
+
+// A Cursor describes a node encountered during Apply.
+// Information about the node and its parent is available
+// from the Node, Parent, Name and Index methods.
+type Cursor struct {
+	parent Expr
+	name   string
+	index  int
+	node   Expr
+	root   *Expr
+}
+
+// Node returns the current Node.
+func (c *Cursor) Node() Expr { return c.node }
+
+// Parent returns the parent of the current Node, or nil for the root.
+func (c *Cursor) Parent() Expr { return c.parent }
+
+// Name returns the name of the parent Node field that contains the
+// current Node, or "" for the root.
+func (c *Cursor) Name() string { return c.name }
+
+// Index reports the index >= 0 of the current Node in the slice of
+// Nodes that contains it, or a value < 0 if the current Node is not
+// part of a slice.
+func (c *Cursor) Index() int { return c.index }
+
+// Replace replaces the current Node with n. The replacement node is
+// not walked by Apply unless Replace is called from pre. Replace panics
+// if n cannot be stored in the parent field holding the current Node.
+func (c *Cursor) Replace(n Expr) {
+	if c.parent == nil {
+		*c.root = n
+		c.node = n
+		return
+	}
+	field := reflect.ValueOf(c.parent).Elem().FieldByName(c.name)
+	if !field.IsValid() {
+		panic(fmt.Sprintf("parser.Apply: %T has no field %s", c.parent, c.name))
+	}
+	if c.index >= 0 {
+		field = field.Index(c.index)
+	}
+	switch {
+	case isNilExpr(n):
+		field.Set(reflect.Zero(field.Type()))
+	case field.Kind() == reflect.Struct:
+		// Lists of value types such as EnumValueExprList.Enums hold
+		// the node itself rather than a pointer to it.
+		value := reflect.ValueOf(n)
+		if value.Type() != reflect.PtrTo(field.Type()) {
+			panic(fmt.Sprintf("parser.Apply: cannot replace %s (%s) of %T with %T", c.name, field.Type(), c.parent, n))
+		}
+		field.Set(value.Elem())
+		n = field.Addr().Interface().(Expr)
+	default:
+		value := reflect.ValueOf(n)
+		if !value.Type().AssignableTo(field.Type()) {
+			panic(fmt.Sprintf("parser.Apply: cannot replace %s (%s) of %T with %T", c.name, field.Type(), c.parent, n))
+		}
+		field.Set(value)
+	}
+	c.node = n
+}
+
+type application struct {
+	pre, post ApplyFunc
+	cursor    Cursor
+}
+
+func (a *application) apply(parent Expr, name string, index int, node Expr, root *Expr) {
+	saved := a.cursor
+	defer func() { a.cursor = saved }()
+
+	a.cursor = Cursor{parent: parent, name: name, index: index, node: node, root: root}
+	if a.pre != nil && !a.pre(&a.cursor) {
+		return
+	}
+	if node = a.cursor.node; !isNilExpr(node) {
+		walkChildren(node, func(name string, index int, child Expr) {
+			a.apply(node, name, index, child, root)
+		})
+	}
+	if a.post != nil && !a.post(&a.cursor) {
+		panic(errAbortApply)
+	}
+}
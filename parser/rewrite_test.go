@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseSingleStatement(t *testing.T, sql string) Expr {
+	t.Helper()
+	stmts, err := NewParser(sql).ParseStatements()
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+	return stmts[0]
+}
+
+func TestApply_ReplaceWithSelf(t *testing.T) {
+	for name, stmts := range parseTestdataStatements(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, stmt := range stmts {
+				expected := stmt.String(0)
+				result := Apply(stmt, func(c *Cursor) bool {
+					c.Replace(c.Node())
+					return true
+				}, nil)
+				require.Equal(t, expected, result.String(0))
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Run("rename tables", func(t *testing.T) {
+		stmt := parseSingleStatement(t, "SELECT a.id FROM db.t1 AS a JOIN db.t2 AS b ON a.id = b.id")
+		result := Apply(stmt, func(c *Cursor) bool {
+			if table, ok := c.Node().(*TableIdentifier); ok && table.Table.Name == "t2" {
+				c.Replace(&TableIdentifier{
+					Database: &Ident{Name: "archive"},
+					Table:    &Ident{Name: "t2_old"},
+				})
+			}
+			return true
+		}, nil)
+		require.Same(t, stmt, result)
+		require.Contains(t, result.String(0), "archive.t2_old AS b")
+		require.Contains(t, result.String(0), "db.t1 AS a")
+	})
+
+	t.Run("inject predicate", func(t *testing.T) {
+		stmt := parseSingleStatement(t, "SELECT id FROM t1 WHERE id > 10")
+		Apply(stmt, nil, func(c *Cursor) bool {
+			if c.Name() == "Expr" {
+				if _, ok := c.Parent().(*WhereExpr); ok {
+					c.Replace(&BinaryExpr{
+						LeftExpr:  c.Node(),
+						Operation: "AND",
+						RightExpr: &BinaryExpr{
+							LeftExpr:  &Ident{Name: "tenant_id"},
+							Operation: "=",
+							RightExpr: &NumberLiteral{Literal: "1"},
+						},
+					})
+				}
+			}
+			return true
+		})
+		require.Equal(t, "id > 10 AND tenant_id = 1", stmt.(*SelectQuery).Where.Expr.String(0))
+	})
+
+	t.Run("replace list items", func(t *testing.T) {
+		stmt := parseSingleStatement(t, "SELECT a, b, c FROM t1")
+		var names []string
+		var indexes []int
+		Apply(stmt, func(c *Cursor) bool {
+			if ident, ok := c.Node().(*Ident); ok {
+				if _, ok := c.Parent().(*ColumnExprList); ok {
+					names = append(names, c.Name())
+					indexes = append(indexes, c.Index())
+					if ident.Name == "b" {
+						c.Replace(&NumberLiteral{Literal: "42"})
+					}
+				}
+			}
+			return true
+		}, nil)
+		require.Equal(t, []string{"Items", "Items", "Items"}, names)
+		require.Equal(t, []int{0, 1, 2}, indexes)
+		require.Equal(t, "a, 42, c", stmt.(*SelectQuery).SelectColumns.String(0))
+	})
+
+	t.Run("replace alter actions", func(t *testing.T) {
+		stmt := parseSingleStatement(t, "ALTER TABLE t1 DROP COLUMN a, DROP COLUMN b")
+		Apply(stmt, func(c *Cursor) bool {
+			if _, ok := c.Node().(*AlterTableDropColumn); ok && c.Index() == 1 {
+				c.Replace(&AlterTableRemoveTTL{})
+				return false
+			}
+			return true
+		}, nil)
+		alter := stmt.(*AlterTable)
+		require.Len(t, alter.AlterExprs, 2)
+		require.IsType(t, &AlterTableRemoveTTL{}, alter.AlterExprs[1])
+		require.Contains(t, alter.String(0), "REMOVE TTL")
+	})
+
+	t.Run("replace root", func(t *testing.T) {
+		stmt := parseSingleStatement(t, "SELECT 1")
+		replacement := &NumberLiteral{Literal: "2"}
+		result := Apply(stmt, func(c *Cursor) bool {
+			require.Nil(t, c.Parent())
+			require.Equal(t, "", c.Name())
+			c.Replace(replacement)
+			return false
+		}, nil)
+		require.Same(t, replacement, result)
+	})
+
+	t.Run("abort traversal", func(t *testing.T) {
+		stmt := parseSingleStatement(t, "SELECT a, b, c FROM t1")
+		var visited []string
+		Apply(stmt, nil, func(c *Cursor) bool {
+			if ident, ok := c.Node().(*Ident); ok {
+				visited = append(visited, ident.Name)
+				return ident.Name != "b"
+			}
+			return true
+		})
+		require.Equal(t, []string{"a", "b"}, visited)
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		stmt := parseSingleStatement(t, "SELECT a FROM t1")
+		require.Panics(t, func() {
+			Apply(stmt, func(c *Cursor) bool {
+				if _, ok := c.Node().(*FromExpr); ok {
+					c.Replace(&Ident{Name: "oops"})
+				}
+				return true
+			}, nil)
+		})
+	})
+	t.Run("nil nodes", func(t *testing.T) {
+		called := false
+		visit := func(c *Cursor) bool {
+			called = true
+			return true
+		}
+		require.Nil(t, Apply(nil, visit, visit))
+		require.False(t, called)
+
+		stmt := parseSingleStatement(t, "SELECT a FROM t1 WHERE a > 1")
+		var nilNodes int
+		result := Apply(stmt, func(c *Cursor) bool {
+			require.NotNil(t, c.Node())
+			if _, ok := c.Node().(*WhereExpr); ok {
+				c.Replace(nil)
+			}
+			return true
+		}, func(c *Cursor) bool {
+			if c.Node() == nil {
+				nilNodes++
+				require.Equal(t, "Where", c.Name())
+			}
+			return true
+		})
+		require.Equal(t, 1, nilNodes)
+		require.Nil(t, result.(*SelectQuery).Where)
+	})
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// SchemaVersion is the version of the JSON encoding produced by MarshalExpr.
+// It must be bumped whenever a node type or field is added, removed or renamed.
+const SchemaVersion = "4.2.0"
+
+//go:embed schema/ast.schema.json
+var astSchema []byte
+
+// JSONSchema returns the JSON Schema describing the document printed by the
+// CLI: the schema version plus the statements encoded by MarshalStatements.
+func JSONSchema() []byte {
+	return astSchema
+}
+
+// interfaceKinds are the AST interfaces that appear as field types.
+var interfaceKinds = map[reflect.Type]string{
+	reflect.TypeOf((*Expr)(nil)).Elem():           "Expr",
+	reflect.TypeOf((*Literal)(nil)).Elem():        "Literal",
+	reflect.TypeOf((*DDL)(nil)).Elem():            "DDL",
+	reflect.TypeOf((*AlterTableExpr)(nil)).Elem(): "AlterTableExpr",
+}
+
+// valueKinds are the structs that appear as field types but are not
+// nodes, so they are encoded without a kind.
+var valueKinds = []reflect.Type{
+	reflect.TypeOf(Comment{}),
+	reflect.TypeOf(Trivia{}),
+}
+
+// generateJSONSchema builds the schema stored in schema/ast.schema.json
+// from the AST struct definitions.
+func generateJSONSchema() ([]byte, error) {
+	kinds := make([]string, 0, len(nodeKinds))
+	for kind := range nodeKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	defs := make(map[string]interface{})
+	for _, kind := range kinds {
+		defs[kind] = structSchema(nodeKinds[kind])
+	}
+	for _, typ := range valueKinds {
+		defs[typ.Name()] = structSchema(typ)
+	}
+	for iface, name := range interfaceKinds {
+		var refs []interface{}
+		for _, kind := range kinds {
+			if reflect.PtrTo(nodeKinds[kind]).Implements(iface) {
+				refs = append(refs, schemaRef(kind))
+			}
+		}
+		defs[name] = map[string]interface{}{"anyOf": refs}
+	}
+	var statements []interface{}
+	for _, kind := range kinds {
+		if isStatement(reflect.New(nodeKinds[kind]).Interface().(Expr)) {
+			statements = append(statements, schemaRef(kind))
+		}
+	}
+	defs["Statement"] = map[string]interface{}{"anyOf": statements}
+
+	schema := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "ClickHouse SQL AST",
+		"version": SchemaVersion,
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"schemaVersion": map[string]interface{}{"const": SchemaVersion},
+			"statements": map[string]interface{}{
+				"type":  "array",
+				"items": schemaRef("Statement"),
+			},
+		},
+		"required":             []string{"schemaVersion", "statements"},
+		"additionalProperties": false,
+		"$defs":                defs,
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func structSchema(typ reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	if _, ok := nodeKinds[typ.Name()]; ok {
+		properties[KindKey] = map[string]interface{}{"const": typ.Name()}
+		required = append(required, KindKey)
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		properties[field.Name] = typeSchema(field.Type)
+		required = append(required, field.Name)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// typeSchema returns the schema of a field value. Pointers, interfaces
+// and slices are nullable since MarshalExpr encodes nil values as null.
+func typeSchema(typ reflect.Type) interface{} {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return nullable(typeSchema(typ.Elem()))
+	case reflect.Interface:
+		return nullable(schemaRef(interfaceKinds[typ]))
+	case reflect.Struct:
+		return schemaRef(typ.Name())
+	case reflect.Slice:
+		return nullable(map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(typ.Elem()),
+		})
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	default:
+		panic("parser: no JSON schema for type " + typ.String())
+	}
+}
+
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/$defs/" + name}
+}
+
+func nullable(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}},
+	}
+}
@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSONSchema fails when the AST structs drift from schema/ast.schema.json.
+// After bumping SchemaVersion, regenerate the schema with `make update_test`.
+func TestJSONSchema(t *testing.T) {
+	schema, err := generateJSONSchema()
+	require.NoError(t, err)
+	g := goldie.New(t,
+		goldie.WithNameSuffix(".schema.json"),
+		goldie.WithDiffEngine(goldie.ColoredDiff),
+		goldie.WithFixtureDir("schema"))
+	g.Assert(t, "ast", schema)
+
+	var committed struct {
+		Version string `json:"version"`
+	}
+	require.NoError(t, json.Unmarshal(JSONSchema(), &committed))
+	require.Equal(t, SchemaVersion, committed.Version, "schema version is out of date")
+}
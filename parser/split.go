@@ -0,0 +1,84 @@
+package parser
+
+// StatementSpan is a statement found by SplitStatements.
+type StatementSpan struct {
+	// Start and End are the offsets of Text in the input.
+	Start Pos
+	End   Pos
+	// Text is the statement without the terminating ';'.
+	Text string
+	// LeadingComments are the comments between the previous statement
+	// and this one.
+	LeadingComments []string
+}
+
+// SplitStatements splits sql into statements at the ';' that are not
+// inside strings, quoted identifiers or comments. Unlike ParseStatements
+// it only tokenizes the input, so it also works for statements the
+// parser doesn't support, and never fails.
+func SplitStatements(sql string) []StatementSpan {
+	var spans []StatementSpan
+	lexer := NewLexer(sql)
+	for {
+		span, terminated := nextStatement(lexer)
+		if span != nil {
+			spans = append(spans, *span)
+		}
+		if !terminated {
+			return spans
+		}
+	}
+}
+
+// nextStatement consumes the tokens of the next statement up to and
+// including its terminating ';', and reports whether there was one before
+// the end of input. The span is nil if there are no tokens before the ';'.
+func nextStatement(lexer *Lexer) (*StatementSpan, bool) {
+	var span *StatementSpan
+	for {
+		comments := len(lexer.comments)
+		err := lexer.consumeToken()
+		token := lexer.lastToken
+		if err == nil && token == nil {
+			return span, false
+		}
+		if err == nil && token.Kind == ";" {
+			return span, true
+		}
+
+		start := lexer.current
+		if err == nil {
+			start = int(tokenStart(token))
+		} else if lexer.truncated {
+			// an unterminated string, quoted identifier or comment runs to the
+			// end of input
+			comment := lexer.peekN(0) == '/'
+			lexer.skipN(len(lexer.input) - lexer.current)
+			if comment {
+				continue
+			}
+		} else {
+			lexer.skipN(1)
+		}
+		if span == nil {
+			span = &StatementSpan{Start: Pos(start)}
+			for _, comment := range lexer.comments[comments:] {
+				span.LeadingComments = append(span.LeadingComments, comment.String)
+			}
+		}
+		span.End = Pos(lexer.current)
+		span.Text = lexer.input[span.Start:span.End]
+	}
+}
+
+// tokenStart returns the offset of the first character of token in the input,
+// including the opening quote of strings and quoted identifiers.
+func tokenStart(token *Token) Pos {
+	if token.Heredoc != "" {
+		return token.Pos - Pos(len(token.Heredoc))
+	}
+	if token.Kind == TokenString || token.Unquoted {
+		return token.Pos - 1
+	}
+	return token.Pos
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		sql      string
+		expected []StatementSpan
+	}{
+		{
+			name: "strings, identifiers and comments",
+			sql:  "-- head\nSELECT 1; /* c1 */ -- c2\n SELECT ';', `a;b` FROM t -- tail\n;;  \n-- trailing",
+			expected: []StatementSpan{
+				{Start: 8, End: 16, Text: "SELECT 1", LeadingComments: []string{"-- head"}},
+				{Start: 34, End: 58, Text: "SELECT ';', `a;b` FROM t", LeadingComments: []string{"/* c1 */", "-- c2"}},
+			},
+		},
+		{
+			name: "unsupported statements",
+			sql:  "SHOW PROCESSLIST; KILL QUERY WHERE query_id = 'x'",
+			expected: []StatementSpan{
+				{Start: 0, End: 16, Text: "SHOW PROCESSLIST"},
+				{Start: 18, End: 49, Text: "KILL QUERY WHERE query_id = 'x'"},
+			},
+		},
+		{
+			name: "invalid number",
+			sql:  "SELECT 0b102; SELECT 2",
+			expected: []StatementSpan{
+				{Start: 0, End: 12, Text: "SELECT 0b102"},
+				{Start: 14, End: 22, Text: "SELECT 2"},
+			},
+		},
+		{
+			name: "unterminated string",
+			sql:  "SELECT 1; SELECT 'a; b",
+			expected: []StatementSpan{
+				{Start: 0, End: 8, Text: "SELECT 1"},
+				{Start: 10, End: 22, Text: "SELECT 'a; b"},
+			},
+		},
+		{
+			name: "unterminated comment",
+			sql:  "SELECT 1 /* a; b",
+			expected: []StatementSpan{
+				{Start: 0, End: 8, Text: "SELECT 1"},
+			},
+		},
+		{
+			name: "nested and hash comments",
+			sql:  "# a; b\nSELECT /* a /* b; */ c; */ 1; #! d; e",
+			expected: []StatementSpan{
+				{Start: 7, End: 35, Text: "SELECT /* a /* b; */ c; */ 1", LeadingComments: []string{"# a; b"}},
+			},
+		},
+		{
+			name:     "no statements",
+			sql:      " ;; -- nothing",
+			expected: nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, SplitStatements(tc.sql))
+		})
+	}
+}
+
+func TestSplitStatements_Testdata(t *testing.T) {
+	for _, dir := range []string{"./testdata/dml", "./testdata/ddl", "./testdata/query", "./testdata/basic"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+		require.NoError(t, err)
+		for _, file := range files {
+			t.Run(file, func(t *testing.T) {
+				fileBytes, err := os.ReadFile(file)
+				require.NoError(t, err)
+				expected, err := NewParser(string(fileBytes)).ParseStatements()
+				require.NoError(t, err)
+
+				spans := SplitStatements(string(fileBytes))
+				require.Len(t, spans, len(expected))
+				for i, span := range spans {
+					require.Equal(t, expected[i].Pos(), span.Start)
+					stmts, err := NewParser(span.Text + ";").ParseStatements()
+					require.NoError(t, err)
+					require.True(t, Equal(expected[i], stmts[0], EqualOptions{IgnorePos: true, IgnoreComments: true}))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxStatementSize is the largest statement a StreamParser accepts.
+const maxStatementSize = 1 << 30
+
+// StreamParser parses statements one at a time from an io.Reader, so that
+// large SQL dumps don't have to be loaded into memory as a whole.
+type StreamParser struct {
+	scanner *bufio.Scanner
+	// offset is the position of the next statement in the whole input.
+	offset int
+	// line is the number of lines before offset, and lineText the text
+	// between the last line break and offset, which is column characters long.
+	line     int
+	lineText string
+	column   int
+	// span is the statement in the last text returned by the scanner.
+	span *StatementSpan
+}
+
+// Statement is a statement read by a StreamParser.
+type Statement struct {
+	Expr Expr
+	// Start and End are the offsets of the statement in the whole input,
+	// from its first token up to the terminating ';', as in StatementSpan.
+	Start Pos
+	End   Pos
+}
+
+// NewStreamParser returns a StreamParser reading statements from r.
+func NewStreamParser(r io.Reader) *StreamParser {
+	s := &StreamParser{scanner: bufio.NewScanner(r)}
+	s.scanner.Buffer(nil, maxStatementSize)
+	s.scanner.Split(s.scanStatement)
+	return s
+}
+
+// Next returns the next statement, or io.EOF when the input is exhausted.
+// Positions in the returned statement and errors are offsets in the whole
+// input. After a *ParseError, Next can be called again to continue with the
+// statement following the next ';'.
+func (s *StreamParser) Next() (*Statement, error) {
+	for s.scanner.Scan() {
+		text := s.scanner.Text()
+		offset, line, lineText, column := s.offset, s.line, s.lineText, s.column
+		s.advance(text)
+
+		p := NewParser(text)
+		stmts, err := p.ParseStatements()
+		if err != nil {
+			if parseErr, ok := err.(*ParseError); ok {
+				parseErr.Pos += Pos(offset)
+				if parseErr.Line == 1 {
+					parseErr.Column += column
+					parseErr.sourceLine = lineText + parseErr.sourceLine
+				}
+				parseErr.Line += line
+			}
+			return nil, err
+		}
+		if len(stmts) == 0 {
+			// only whitespace or comments before ';'
+			continue
+		}
+		shiftPos(stmts[0], Pos(offset))
+		return &Statement{
+			Expr:  stmts[0],
+			Start: s.span.Start + Pos(offset),
+			End:   s.span.End + Pos(offset),
+		}, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// advance moves the stream position past text.
+func (s *StreamParser) advance(text string) {
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		s.line += strings.Count(text, "\n")
+		s.lineText = text[i+1:]
+	} else {
+		s.lineText += text
+	}
+	s.column = utf8.RuneCountInString(s.lineText)
+	s.offset += len(text)
+}
+
+// scanStatement is a bufio.SplitFunc returning the text up to and including
+// the next ';' found by nextStatement, followed by the comments on the rest of
+// its line, which trail the statement. It records the statement in s.span.
+func (s *StreamParser) scanStatement(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	lexer := NewLexer(string(data))
+	span, terminated := nextStatement(lexer)
+	if !terminated {
+		// the statement may continue in data that has not been read yet
+		if atEOF && len(data) > 0 {
+			s.span = span
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+	end, ok := trailingCommentsEnd(lexer, atEOF)
+	if !ok {
+		return 0, nil, nil
+	}
+	s.span = span
+	return end, data[:end], nil
+}
+
+// trailingCommentsEnd returns the offset after the comments following the
+// ';' just consumed by lexer on the same line, or false if more input is
+// needed to tell.
+func trailingCommentsEnd(lexer *Lexer, atEOF bool) (int, bool) {
+	end := lexer.current
+	comments := len(lexer.comments)
+	err := lexer.consumeToken()
+	for _, comment := range lexer.comments[comments:] {
+		if strings.Contains(lexer.input[end:comment.Pos], "\n") {
+			return end, true
+		}
+		end = int(comment.End)
+	}
+	next := lexer.current
+	if lexer.lastToken != nil {
+		next = int(tokenStart(lexer.lastToken))
+	}
+	switch {
+	case strings.Contains(lexer.input[end:next], "\n"):
+		return end, true
+	case !atEOF && lexer.current+1 >= len(lexer.input):
+		// the last token may be cut short, e.g. the "-" of a "--" comment
+		return 0, false
+	case lexer.lastToken != nil, err != nil && !lexer.truncated:
+		// the next statement starts on the same line
+		return end, true
+	case atEOF:
+		return len(lexer.input), true
+	default:
+		return 0, false
+	}
+}
+
+// shiftPos adds delta to every position in the tree rooted at node.
+func shiftPos(node Expr, delta Pos) {
+	seen := make(map[uintptr]bool)
+	var shift func(v reflect.Value)
+	shift = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if v.IsNil() || seen[v.Pointer()] {
+				return
+			}
+			seen[v.Pointer()] = true
+			shift(v.Elem())
+		case reflect.Interface:
+			if !v.IsNil() {
+				shift(v.Elem())
+			}
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				shift(v.Field(i))
+			}
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				shift(v.Index(i))
+			}
+		case reflect.Int:
+			if v.Type() == posType {
+				v.SetInt(v.Int() + int64(delta))
+			}
+		}
+	}
+	shift(reflect.ValueOf(node))
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readAllStatements(t *testing.T, s *StreamParser) []*Statement {
+	t.Helper()
+	var stmts []*Statement
+	for {
+		stmt, err := s.Next()
+		if errors.Is(err, io.EOF) {
+			return stmts
+		}
+		require.NoError(t, err)
+		stmts = append(stmts, stmt)
+	}
+}
+
+func TestStreamParser(t *testing.T) {
+	for _, dir := range []string{"./testdata/dml", "./testdata/ddl", "./testdata/query", "./testdata/basic"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+		require.NoError(t, err)
+		for _, file := range files {
+			t.Run(file, func(t *testing.T) {
+				fileBytes, err := os.ReadFile(file)
+				require.NoError(t, err)
+				expected, err := NewParser(string(fileBytes)).ParseStatements()
+				require.NoError(t, err)
+
+				reader := iotest.OneByteReader(strings.NewReader(string(fileBytes)))
+				stmts := readAllStatements(t, NewStreamParser(reader))
+				require.Len(t, stmts, len(expected))
+				for i := range expected {
+					require.True(t, Equal(expected[i], stmts[i].Expr, EqualOptions{IgnorePos: true}))
+					require.Equal(t, expected[i].Pos(), stmts[i].Expr.Pos())
+				}
+			})
+		}
+	}
+}
+
+func TestStreamParser_Testdata(t *testing.T) {
+	for _, dir := range []string{"./testdata/dml", "./testdata/ddl", "./testdata/query", "./testdata/basic"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+		require.NoError(t, err)
+		for _, file := range files {
+			t.Run(file, func(t *testing.T) {
+				fileBytes, err := os.ReadFile(file)
+				require.NoError(t, err)
+				spans := SplitStatements(string(fileBytes))
+
+				reader := iotest.OneByteReader(strings.NewReader(string(fileBytes)))
+				stmts := readAllStatements(t, NewStreamParser(reader))
+				require.Len(t, stmts, len(spans))
+				for i, span := range spans {
+					require.Equal(t, span.Start, stmts[i].Start)
+					require.Equal(t, span.End, stmts[i].End)
+				}
+			})
+		}
+	}
+}
+
+func TestStreamParser_Offsets(t *testing.T) {
+	sql := "SELECT ';' AS a; -- comment; with semicolon\n" +
+		"/* block; comment */ SELECT `b;c` FROM t1;;\n" +
+		"DROP TABLE t2"
+	stmts := readAllStatements(t, NewStreamParser(strings.NewReader(sql)))
+	require.Len(t, stmts, 3)
+	require.Equal(t, "SELECT ';' AS a", sql[stmts[0].Expr.Pos():stmts[0].Expr.End()])
+	require.Equal(t, "SELECT `b;c` FROM t1", sql[stmts[1].Expr.Pos():stmts[1].Expr.End()])
+	require.Equal(t, "DROP TABLE t2", sql[stmts[2].Expr.Pos():stmts[2].Expr.End()])
+}
+
+func TestStreamParser_Spans(t *testing.T) {
+	sql := "-- head\nSELECT 1; /* c1 */ -- c2\n" +
+		"/* c3 */ INSERT INTO t VALUES ('a;b') -- c4\n;\n" +
+		"# c5\nSELECT 2 /* c6 */"
+	reader := iotest.OneByteReader(strings.NewReader(sql))
+	stmts := readAllStatements(t, NewStreamParser(reader))
+	require.Len(t, stmts, 3)
+	require.Equal(t, "SELECT 1", sql[stmts[0].Start:stmts[0].End])
+	require.Equal(t, "INSERT INTO t VALUES ('a;b')", sql[stmts[1].Start:stmts[1].End])
+	require.Equal(t, "SELECT 2", sql[stmts[2].Start:stmts[2].End])
+}
+
+func TestStreamParser_Error(t *testing.T) {
+	sql := "SELECT 1;\nSELECT 'é', 2; SELECT * FROM t GROUP a;\nSELECT 3"
+	s := NewStreamParser(strings.NewReader(sql))
+
+	stmt, err := s.Next()
+	require.NoError(t, err)
+	require.Equal(t, 0, int(stmt.Start))
+
+	_, err = s.Next()
+	require.NoError(t, err)
+
+	_, err = s.Next()
+	var parseErr *ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, strings.Index(sql, "GROUP a")+6, int(parseErr.Pos))
+	require.Equal(t, 2, parseErr.Line)
+	require.Equal(t, 38, parseErr.Column)
+	require.Equal(t, "line 2:38 expected BY, but got \"a\"\n"+
+		"SELECT 'é', 2; SELECT * FROM t GROUP a;\n"+
+		"                                     ^\n", parseErr.Error())
+
+	stmt, err = s.Next()
+	require.NoError(t, err)
+	require.Equal(t, strings.Index(sql, "SELECT 3"), int(stmt.Start))
+	require.Equal(t, len(sql), int(stmt.End))
+
+	_, err = s.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
@@ -23,6 +23,7 @@ ALTER ROLE r1_01293@'%';
 ALTER ROLE r2_01293@'%.myhost.com';
 
 -- Format SQL:
+-- Tags: no-parallel
 ALTER ROLE r1_01293;
 ALTER ROLE r1_01293 ON ON CLUSTER cluster_1 RENAME TO r2_01293;
 ALTER ROLE r1_01293 RENAME TO r2_01293, r3_01293 RENAME TO r4_01293;
@@ -25,6 +25,7 @@ CREATE ROLE r1_01293@'%';
 CREATE ROLE r2_01293@'%.myhost.com';
 
 -- Format SQL:
+-- Tags: no-parallel
 CREATE ROLE r1_01293;
 CREATE ROLE r1_01293 ON ON CLUSTER cluster_1;
 CREATE ROLE r1_01293, r2_01293;
@@ -28,6 +28,11 @@ TTL f3 + INTERVAL 6 MONTH
 ORDER BY (f1,f2,f3)
 
 -- Format SQL:
+-- It's a short link events table
+/**
+    * @name Short link events
+    * @description It's a short link events table
+ */
 CREATE TABLE IF NOT EXISTS test.events_local
 (
   f0 String,
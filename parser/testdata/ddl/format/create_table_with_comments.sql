@@ -0,0 +1,25 @@
+-- Origin SQL:
+-- events received from the tracker
+CREATE TABLE IF NOT EXISTS events ( -- one row per event
+    -- unique id of the event
+    id UInt64, -- generated by the tracker
+    /* when the event happened */ ts DateTime,
+    name String COMMENT 'event name' -- lower case
+) ENGINE = MergeTree -- replicated in production
+ORDER BY (id, ts); /* done */
+
+
+-- Format SQL:
+-- events received from the tracker
+CREATE TABLE IF NOT EXISTS events
+(
+  -- one row per event
+  -- unique id of the event
+  id UInt64, -- generated by the tracker
+  /* when the event happened */
+  ts DateTime,
+  name String COMMENT 'event name' -- lower case
+)
+ENGINE = MergeTree
+-- replicated in production
+ORDER BY (id, ts) /* done */;
@@ -9,8 +9,10 @@ OPTIMIZE TABLE table DEDUPLICATE BY COLUMNS('column-matched-by-regex') EXCEPT co
 OPTIMIZE TABLE table DEDUPLICATE BY COLUMNS('column-matched-by-regex') EXCEPT (colX, colY);
 
 -- Format SQL:
-OPTIMIZE TABLE table DEDUPLICATE;
-OPTIMIZE TABLE table DEDUPLICATE BY *;
+OPTIMIZE TABLE table DEDUPLICATE -- all columns
+;
+OPTIMIZE TABLE table DEDUPLICATE BY * -- excludes MATERIALIZED and ALIAS columns
+;
 OPTIMIZE TABLE table DEDUPLICATE BY colX, colY, colZ;
 OPTIMIZE TABLE table DEDUPLICATE BY * EXCEPT colX;
 OPTIMIZE TABLE table DEDUPLICATE BY * EXCEPT (colX, colY);
@@ -6,4 +6,4 @@ SELECT arrayConcat([1, 2], [3, 4], [5, 6]) AS res, f1["abc"] as f2
 
 SELECT 
   arrayConcat([1, 2], [3, 4], [5, 6]) AS res,
-  f1['abc'] AS f2;
+  f1["abc"] AS f2;
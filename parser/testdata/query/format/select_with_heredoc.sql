@@ -0,0 +1,12 @@
+-- Origin SQL:
+SELECT $$it's a "raw" \n string$$ AS a, $body$SELECT 1; -- not a comment
+$body$ AS b, length($x$$$x$) AS c;
+
+
+-- Format SQL:
+
+SELECT 
+  $$it's a "raw" \n string$$ AS a,
+  $body$SELECT 1; -- not a comment
+$body$ AS b,
+  length($x$$$x$) AS c;
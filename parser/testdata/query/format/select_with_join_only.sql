@@ -7,4 +7,4 @@ SELECT * FROM "t1" JOIN "t2" ON true
 SELECT 
   *
 FROM
-  't1' JOIN 't2' ON true;
+  "t1" JOIN "t2" ON true;
@@ -0,0 +1,15 @@
+-- Origin SQL:
+SELECT 1_000_000 AS a, 0b1010 AS b, 0xFF_FF AS c, 0x1.8p3 AS d, 1e-3 AS e, -inf AS f, nan AS g, inf AS h;
+
+
+-- Format SQL:
+
+SELECT 
+  1_000_000 AS a,
+  0b1010 AS b,
+  0xFF_FF AS c,
+  0x1.8p3 AS d,
+  1e-3 AS e,
+  -inf AS f,
+  nan AS g,
+  inf AS h;
@@ -0,0 +1,17 @@
+-- Origin SQL:
+SELECT {col:Identifier}, count() AS cnt
+FROM {db:Identifier}.events
+WHERE id = {id:UInt64} AND ts > {from:DateTime} AND tag IN {tags:Array(String)}
+GROUP BY {col:Identifier};
+
+
+-- Format SQL:
+
+SELECT 
+  {col:Identifier},
+  count() AS cnt
+FROM
+  {db:Identifier}.events
+WHERE
+  id = {id:UInt64} AND ts > {from:DateTime} AND tag IN {tags:Array(String)}
+GROUP BY {col:Identifier};
@@ -4,10 +4,10 @@ WITH "abc" AS (SELECT 1 AS a) SELECT * FROM "abc"
 
 -- Format SQL:
 WITH
-  'abc' AS (
+  "abc" AS (
     SELECT 
       1 AS a)
 SELECT 
   *
 FROM
-  'abc';
+  "abc";
@@ -2,4 +2,5 @@
 SET max_threads = 1, max_insert_threads = 0, max_block_size = 8192, min_insert_block_size_rows = 8192, min_insert_block_size_bytes = 1048576; -- lower memory usage
 
 -- Format SQL:
-SET max_threads=1, max_insert_threads=0, max_block_size=8192, min_insert_block_size_rows=8192, min_insert_block_size_bytes=1048576;
+SET max_threads=1, max_insert_threads=0, max_block_size=8192, min_insert_block_size_rows=8192, min_insert_block_size_bytes=1048576 -- lower memory usage
+;
@@ -0,0 +1,5 @@
+SELECT
+    event_date BETWEEN toDate('2024-01-01') AND today() - 1 AS in_range,
+    price NOT BETWEEN 10 AND 20 * 2 AS outside
+FROM events
+WHERE event_date BETWEEN '2024-01-01' AND '2024-02-01' AND user_id NOT BETWEEN 100 AND 200 OR is_test;
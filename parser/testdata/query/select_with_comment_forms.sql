@@ -0,0 +1,4 @@
+#!/usr/bin/env clickhouse-client --multiquery
+# a hash comment; with a semicolon
+SELECT 1 /* outer /* inner; */ still a comment; */ AS a; # trailing; comment
+SELECT 2 AS b; -- done
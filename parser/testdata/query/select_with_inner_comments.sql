@@ -0,0 +1,13 @@
+SELECT
+    a, -- the a column
+    -- the b column
+    b,
+    count(*) /* all rows */ AS c
+FROM t1
+    -- join the users
+    LEFT JOIN t2 ON t1.id = t2.id -- same id
+        AND t2.active /* only active */
+WHERE a > 1 -- positive
+    -- and small
+    AND b < 10
+GROUP BY a, b;
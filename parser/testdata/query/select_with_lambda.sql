@@ -0,0 +1,6 @@
+SELECT
+    arrayMap(x -> x * 2, arr) AS doubled,
+    arrayFilter((k, v) -> v > 0, keys, vals) AS positive_keys,
+    arraySort(x -> -x, a) AS sorted_desc,
+    arrayReduce('sum', arrayMap(x -> if(x > 0, x, 0), arr)) AS total
+FROM t;
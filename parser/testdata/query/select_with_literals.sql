@@ -0,0 +1,13 @@
+SELECT
+    DATE '2024-01-01',
+    TIMESTAMP '2024-01-01 00:00:00' AS ts,
+    (1, 'x'),
+    (),
+    (1),
+    (1,),
+    {'a': 1, 'b': 2},
+    {},
+    {'k': (1, 2)}['k'].1,
+    arrayMap((k, v) -> k + v, keys, vals)
+FROM t
+WHERE d >= DATE '2024-01-01' AND (a, b) IN ((1, 2), (3, 4)) AND id = {id:UInt64};
@@ -0,0 +1,9 @@
+SELECT
+    quantile(0.95)(latency) AS p95,
+    quantiles(0.5, 0.9)(latency) AS ps,
+    sequenceMatch('(?1)(?2)')(ts, event = 'view', event = 'buy') AS converted,
+    topK(10)(keyword) AS top_keywords,
+    uniqUpTo(3)(DISTINCT user_id) AS few_users,
+    quantileIf(0.5)(latency, status = 200) AS median_ok,
+    count(DISTINCT user_id) AS users
+FROM events;
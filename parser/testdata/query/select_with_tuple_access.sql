@@ -0,0 +1,16 @@
+SELECT
+    tup.1,
+    nested.2.1,
+    tupleElement(x, 'a').name,
+    (1, 'a').2,
+    m['key'],
+    arr[1][2],
+    1 + arr[length(arr)],
+    t.col[1],
+    db.t.col.field,
+    json.a.b.:Int64,
+    json.:String,
+    "quoted".1,
+    1.5
+FROM t
+WHERE tup.1 > 0;
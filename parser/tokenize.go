@@ -0,0 +1,66 @@
+package parser
+
+import "strings"
+
+// Tokenize splits sql into tokens, including whitespace and comments, so
+// that concatenating the Raw text of the tokens gives back sql. Pos and End
+// are the exact offsets of Raw in sql, and keyword tokens have the
+// upper-case keyword in String.
+//
+// On a lexer error Tokenize returns the tokens before the error and a
+// *ParseError pointing at it.
+func Tokenize(sql string) ([]Token, error) {
+	p := NewParser(sql)
+	var tokens []Token
+	offset := 0
+	for {
+		comments := len(p.lexer.comments)
+		err := p.lexer.consumeToken()
+		token := p.lexer.lastToken
+		start := p.lexer.current
+		if token != nil {
+			start = int(tokenStart(token))
+		}
+		for _, comment := range p.lexer.comments[comments:] {
+			tokens = appendWhitespace(tokens, sql, offset, int(comment.Pos))
+			comment.Raw = comment.String
+			tokens = append(tokens, *comment)
+			offset = int(comment.End)
+		}
+		tokens = appendWhitespace(tokens, sql, offset, start)
+		if err == nil {
+			// errors inside a token, such as an invalid escape sequence,
+			// are only recorded
+			err = p.lexer.err
+		}
+		if err != nil {
+			return tokens, p.wrapError(err)
+		}
+		if token == nil {
+			return tokens, nil
+		}
+
+		raw := *token
+		raw.Pos = Pos(start)
+		raw.End = Pos(p.lexer.current)
+		raw.Raw = sql[start:p.lexer.current]
+		if raw.Kind == TokenKeyword {
+			raw.String = strings.ToUpper(raw.String)
+		}
+		tokens = append(tokens, raw)
+		offset = p.lexer.current
+	}
+}
+
+func appendWhitespace(tokens []Token, sql string, start, end int) []Token {
+	if start == end {
+		return tokens
+	}
+	return append(tokens, Token{
+		Pos:    Pos(start),
+		End:    Pos(end),
+		Kind:   TokenWhitespace,
+		String: sql[start:end],
+		Raw:    sql[start:end],
+	})
+}
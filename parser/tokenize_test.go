@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	sql := "select `a b`, 'x' -- c\nFROM t1 /* d */;"
+	tokens, err := Tokenize(sql)
+	require.NoError(t, err)
+
+	type token struct {
+		Kind   TokenKind
+		String string
+		Raw    string
+	}
+	var got []token
+	for _, tok := range tokens {
+		require.Equal(t, tok.Raw, sql[tok.Pos:tok.End])
+		got = append(got, token{tok.Kind, tok.String, tok.Raw})
+	}
+	require.Equal(t, []token{
+		{TokenKeyword, "SELECT", "select"},
+		{TokenWhitespace, " ", " "},
+		{TokenIdent, "a b", "`a b`"},
+		{",", ",", ","},
+		{TokenWhitespace, " ", " "},
+		{TokenString, "x", "'x'"},
+		{TokenWhitespace, " ", " "},
+		{TokenComment, "-- c", "-- c"},
+		{TokenWhitespace, "\n", "\n"},
+		{TokenKeyword, "FROM", "FROM"},
+		{TokenWhitespace, " ", " "},
+		{TokenIdent, "t1", "t1"},
+		{TokenWhitespace, " ", " "},
+		{TokenComment, "/* d */", "/* d */"},
+		{";", ";", ";"},
+	}, got)
+
+	tokens, err = Tokenize("")
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+}
+
+func TestTokenize_Error(t *testing.T) {
+	tokens, err := Tokenize("SELECT 'abc")
+	require.Len(t, tokens, 2)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, Pos(7), parseErr.Pos)
+	require.Equal(t, 8, parseErr.Column)
+
+	tokens, err = Tokenize(`SELECT 'a\x4'`)
+	require.Len(t, tokens, 2)
+	require.ErrorAs(t, err, &parseErr)
+	require.Equal(t, "invalid hexadecimal escape sequence", parseErr.Message)
+	require.Equal(t, Pos(9), parseErr.Pos)
+}
+
+func TestTokenize_Testdata(t *testing.T) {
+	for _, dir := range []string{"./testdata/dml", "./testdata/ddl", "./testdata/query", "./testdata/basic"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+		require.NoError(t, err)
+		for _, file := range files {
+			t.Run(file, func(t *testing.T) {
+				fileBytes, err := os.ReadFile(file)
+				require.NoError(t, err)
+				tokens, err := Tokenize(string(fileBytes))
+				require.NoError(t, err)
+
+				var builder strings.Builder
+				var end Pos
+				for _, token := range tokens {
+					require.Equal(t, end, token.Pos)
+					builder.WriteString(token.Raw)
+					end = token.End
+				}
+				require.Equal(t, string(fileBytes), builder.String())
+			})
+		}
+	}
+}
@@ -0,0 +1,542 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Expr) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling v.Visit(node);
+// node must not be nil. If the visitor w returned by v.Visit(node) is not nil,
+// Walk is invoked recursively with visitor w for each of the non-nil children
+// of node in source order, followed by a call of w.Visit(nil).
+func Walk(v Visitor, node Expr) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	walkChildren(node, func(_ string, _ int, child Expr) {
+		Walk(v, child)
+	})
+	v.Visit(nil)
+}
+
+type inspector func(Expr) bool
+
+func (f inspector) Visit(node Expr) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Expr, f func(Expr) bool) {
+	Walk(inspector(f), node)
+}
+
+// childFunc receives a child node together with the name of the struct field
+// holding it and, for slice fields, the index of the element (-1 otherwise).
+type childFunc func(name string, index int, child Expr)
+
+func (f childFunc) field(name string, child Expr) {
+	if !isNilExpr(child) {
+		f(name, -1, child)
+	}
+}
+
+func eachItem[T Expr](f childFunc, name string, list []T) {
+	for i, item := range list {
+		if !isNilExpr(item) {
+			f(name, i, item)
+		}
+	}
+}
+
+// isNilExpr reports whether expr is nil or holds a nil pointer.
+func isNilExpr(expr Expr) bool {
+	if expr == nil {
+		return true
+	}
+	v := reflect.ValueOf(expr)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// walkChildren calls f for each non-nil child of node in source order.
+func walkChildren(node Expr, f childFunc) { // nolint: funlen
+	switch n := node.(type) {
+
+	// Leaf nodes
+	case *OperationExpr, *NullLiteral, *NumberLiteral, *StringLiteral,
+		*AlterTableRemoveTTL, *WindowFrameCurrentRow, *WindowFrameUnbounded, *SystemDropExpr,
+		*BadStatement:
+		// nothing to do
+
+	// Expressions
+	case *Ident:
+		f.field("Param", n.Param)
+	case *QueryParam:
+		f.field("Name", n.Name)
+		f.field("Type", n.Type)
+	case *TernaryExpr:
+		f.field("Condition", n.Condition)
+		f.field("TrueExpr", n.TrueExpr)
+		f.field("FalseExpr", n.FalseExpr)
+	case *BinaryExpr:
+		f.field("LeftExpr", n.LeftExpr)
+		f.field("RightExpr", n.RightExpr)
+	case *UnaryExpr:
+		f.field("Expr", n.Expr)
+	case *NotExpr:
+		f.field("Expr", n.Expr)
+	case *NegateExpr:
+		f.field("Expr", n.Expr)
+	case *GlobalInExpr:
+		f.field("Expr", n.Expr)
+	case *LambdaExpr:
+		eachItem(f, "Params", n.Params)
+		f.field("Body", n.Body)
+	case *BetweenExpr:
+		f.field("Expr", n.Expr)
+		f.field("Low", n.Low)
+		f.field("High", n.High)
+	case *IsNullExpr:
+		f.field("Expr", n.Expr)
+	case *IsNotNullExpr:
+		f.field("Expr", n.Expr)
+	case *AliasExpr:
+		f.field("Expr", n.Expr)
+		f.field("Alias", n.Alias)
+	case *NotNullLiteral:
+		f.field("NullLiteral", n.NullLiteral)
+	case *NestedIdentifier:
+		f.field("Ident", n.Ident)
+		f.field("DotIdent", n.DotIdent)
+	case *ColumnIdentifier:
+		f.field("Database", n.Database)
+		f.field("Table", n.Table)
+		f.field("Column", n.Column)
+	case *TableIdentifier:
+		f.field("Database", n.Database)
+		f.field("Table", n.Table)
+	case *UUID:
+		f.field("Value", n.Value)
+	case *RatioExpr:
+		f.field("Numerator", n.Numerator)
+		f.field("Denominator", n.Denominator)
+	case *EnumValueExpr:
+		f.field("Name", n.Name)
+		f.field("Value", n.Value)
+	case *EnumValueExprList:
+		for i := range n.Enums {
+			f("Enums", i, &n.Enums[i])
+		}
+	case *IntervalExpr:
+		f.field("Expr", n.Expr)
+		f.field("Unit", n.Unit)
+	case *ExtractExpr:
+		f.field("Interval", n.Interval)
+		f.field("FromExpr", n.FromExpr)
+	case *CastExpr:
+		f.field("Expr", n.Expr)
+		f.field("AsType", n.AsType)
+	case *CaseExpr:
+		f.field("Expr", n.Expr)
+		eachItem(f, "Whens", n.Whens)
+		f.field("Else", n.Else)
+	case *WhenExpr:
+		f.field("When", n.When)
+		f.field("Then", n.Then)
+		f.field("Else", n.Else)
+	case *FunctionExpr:
+		f.field("Name", n.Name)
+		f.field("Parameters", n.Parameters)
+		f.field("Args", n.Args)
+	case *WindowFunctionExpr:
+		f.field("Function", n.Function)
+		f.field("OverExpr", n.OverExpr)
+	case *ParamExprList:
+		f.field("Items", n.Items)
+	case *ArrayParamList:
+		f.field("Items", n.Items)
+	case *IndexExpr:
+		f.field("Object", n.Object)
+		f.field("Index", n.Index)
+	case *TupleAccessExpr:
+		f.field("Tuple", n.Tuple)
+		f.field("Index", n.Index)
+	case *JSONPathExpr:
+		f.field("Object", n.Object)
+		eachItem(f, "Path", n.Path)
+		f.field("Type", n.Type)
+	case *TypedLiteral:
+		f.field("Type", n.Type)
+		f.field("Value", n.Value)
+	case *TupleLiteral:
+		f.field("Items", n.Items)
+	case *MapLiteral:
+		eachItem(f, "Entries", n.Entries)
+	case *MapEntry:
+		f.field("Key", n.Key)
+		f.field("Value", n.Value)
+	case *ColumnExprList:
+		eachItem(f, "Items", n.Items)
+
+	// Column definitions and types
+	case *Column:
+		f.field("Name", n.Name)
+		f.field("Type", n.Type)
+		f.field("Nullable", n.Nullable)
+		f.field("NotNull", n.NotNull)
+		f.field("Comment", n.Comment)
+		f.field("Property", n.Property)
+		f.field("Codec", n.Codec)
+		f.field("TTL", n.TTL)
+		f.field("CompressionCodec", n.CompressionCodec)
+	case *ScalarTypeExpr:
+		f.field("Name", n.Name)
+	case *PropertyTypeExpr:
+		f.field("Name", n.Name)
+	case *ColumnTypeExpr:
+		f.field("Name", n.Name)
+	case *TypeWithParamsExpr:
+		f.field("Name", n.Name)
+		eachItem(f, "Params", n.Params)
+	case *ComplexTypeExpr:
+		f.field("Name", n.Name)
+		eachItem(f, "Params", n.Params)
+	case *NestedTypeExpr:
+		f.field("Name", n.Name)
+		eachItem(f, "Columns", n.Columns)
+	case *CompressionCodec:
+		f.field("Name", n.Name)
+		f.field("Level", n.Level)
+	case *DefaultExpr:
+		f.field("Expr", n.Expr)
+	case *ConstraintExpr:
+		f.field("Constraint", n.Constraint)
+		f.field("Expr", n.Expr)
+	case *TableIndex:
+		f.field("Name", n.Name)
+		f.field("ColumnExpr", n.ColumnExpr)
+		f.field("ColumnType", n.ColumnType)
+		f.field("Granularity", n.Granularity)
+	case *RemovePropertyType:
+		f.field("PropertyType", n.PropertyType)
+
+	// Table definitions
+	case *TableSchemaExpr:
+		eachItem(f, "Columns", n.Columns)
+		f.field("AliasTable", n.AliasTable)
+		f.field("TableFunction", n.TableFunction)
+	case *TableArgListExpr:
+		eachItem(f, "Args", n.Args)
+	case *TableFunctionExpr:
+		f.field("Name", n.Name)
+		f.field("Args", n.Args)
+	case *OnClusterExpr:
+		f.field("Expr", n.Expr)
+	case *PartitionExpr:
+		f.field("ID", n.ID)
+		f.field("Expr", n.Expr)
+	case *PartitionByExpr:
+		f.field("Expr", n.Expr)
+	case *PrimaryKeyExpr:
+		f.field("Expr", n.Expr)
+	case *SampleByExpr:
+		f.field("Expr", n.Expr)
+	case *TTLExpr:
+		f.field("Expr", n.Expr)
+	case *TTLExprList:
+		eachItem(f, "Items", n.Items)
+	case *OrderByExpr:
+		f.field("Expr", n.Expr)
+	case *OrderByListExpr:
+		eachItem(f, "Items", n.Items)
+	case *SettingsExpr:
+		f.field("Name", n.Name)
+		f.field("Expr", n.Expr)
+	case *SettingsExprList:
+		eachItem(f, "Items", n.Items)
+	case *EngineExpr:
+		f.field("Params", n.Params)
+		f.field("PrimaryKey", n.PrimaryKey)
+		f.field("PartitionBy", n.PartitionBy)
+		f.field("SampleBy", n.SampleBy)
+		f.field("TTLExprList", n.TTLExprList)
+		f.field("SettingsExprList", n.SettingsExprList)
+		f.field("OrderByListExpr", n.OrderByListExpr)
+	case *DestinationExpr:
+		f.field("TableIdentifier", n.TableIdentifier)
+	case *SubQueryExpr:
+		f.field("Select", n.Select)
+	case *WithTimeoutExpr:
+		f.field("Expr", n.Expr)
+		f.field("Number", n.Number)
+
+	// Select queries
+	case *SelectQuery:
+		f.field("With", n.With)
+		f.field("Top", n.Top)
+		f.field("SelectColumns", n.SelectColumns)
+		f.field("From", n.From)
+		f.field("ArrayJoin", n.ArrayJoin)
+		f.field("Window", n.Window)
+		f.field("Prewhere", n.Prewhere)
+		f.field("Where", n.Where)
+		f.field("GroupBy", n.GroupBy)
+		f.field("Having", n.Having)
+		f.field("OrderBy", n.OrderBy)
+		f.field("LimitBy", n.LimitBy)
+		f.field("Limit", n.Limit)
+		f.field("Settings", n.Settings)
+		f.field("UnionAll", n.UnionAll)
+		f.field("UnionDistinct", n.UnionDistinct)
+		f.field("Except", n.Except)
+	case *WithExpr:
+		eachItem(f, "CTEs", n.CTEs)
+	case *CTEExpr:
+		f.field("Expr", n.Expr)
+		f.field("Alias", n.Alias)
+	case *TopExpr:
+		f.field("Number", n.Number)
+	case *FromExpr:
+		f.field("Expr", n.Expr)
+	case *TableExpr:
+		f.field("Expr", n.Expr)
+		f.field("Alias", n.Alias)
+	case *JoinExpr:
+		f.field("Left", n.Left)
+		f.field("SampleRatio", n.SampleRatio)
+		f.field("Right", n.Right)
+		f.field("Constraints", n.Constraints)
+	case *JoinConstraintExpr:
+		f.field("On", n.On)
+		f.field("Using", n.Using)
+	case *OnExpr:
+		f.field("On", n.On)
+	case *UsingExpr:
+		f.field("Using", n.Using)
+	case *SampleRatioExpr:
+		f.field("Ratio", n.Ratio)
+		f.field("Offset", n.Offset)
+	case *ArrayJoinExpr:
+		f.field("Expr", n.Expr)
+	case *WindowExpr:
+		f.field("Name", n.Name)
+		f.field("WindowConditionExpr", n.WindowConditionExpr)
+	case *WindowConditionExpr:
+		f.field("PartitionBy", n.PartitionBy)
+		f.field("OrderBy", n.OrderBy)
+		f.field("Frame", n.Frame)
+	case *WindowFrameExpr:
+		f.field("Extend", n.Extend)
+	case *WindowFrameExtendExpr:
+		f.field("Expr", n.Expr)
+	case *WindowFrameRangeExpr:
+		f.field("BetweenExpr", n.BetweenExpr)
+		f.field("AndExpr", n.AndExpr)
+	case *WindowFrameNumber:
+		f.field("Number", n.Number)
+	case *PrewhereExpr:
+		f.field("Expr", n.Expr)
+	case *WhereExpr:
+		f.field("Expr", n.Expr)
+	case *GroupByExpr:
+		f.field("Expr", n.Expr)
+	case *HavingExpr:
+		f.field("Expr", n.Expr)
+	case *LimitExpr:
+		f.field("Limit", n.Limit)
+		f.field("Offset", n.Offset)
+	case *LimitByExpr:
+		f.field("Limit", n.Limit)
+		f.field("ByExpr", n.ByExpr)
+
+	// DDL statements
+	case *CreateDatabase:
+		f.field("Name", n.Name)
+		f.field("OnCluster", n.OnCluster)
+		f.field("Engine", n.Engine)
+	case *CreateTable:
+		f.field("Name", n.Name)
+		f.field("UUID", n.UUID)
+		f.field("OnCluster", n.OnCluster)
+		f.field("TableSchema", n.TableSchema)
+		f.field("Engine", n.Engine)
+		f.field("SubQuery", n.SubQuery)
+	case *CreateMaterializedView:
+		f.field("Name", n.Name)
+		f.field("UUID", n.UUID)
+		f.field("OnCluster", n.OnCluster)
+		f.field("TableSchema", n.TableSchema)
+		f.field("Destination", n.Destination)
+		f.field("Engine", n.Engine)
+		f.field("SubQuery", n.SubQuery)
+	case *CreateView:
+		f.field("Name", n.Name)
+		f.field("UUID", n.UUID)
+		f.field("OnCluster", n.OnCluster)
+		f.field("TableSchema", n.TableSchema)
+		f.field("SubQuery", n.SubQuery)
+	case *CreateLiveView:
+		f.field("Name", n.Name)
+		f.field("UUID", n.UUID)
+		f.field("OnCluster", n.OnCluster)
+		f.field("WithTimeout", n.WithTimeout)
+		f.field("Destination", n.Destination)
+		f.field("TableSchema", n.TableSchema)
+		f.field("SubQuery", n.SubQuery)
+	case *CreateFunction:
+		f.field("FunctionName", n.FunctionName)
+		f.field("OnCluster", n.OnCluster)
+		f.field("Params", n.Params)
+		f.field("Expr", n.Expr)
+	case *CreateRole:
+		eachItem(f, "RoleNames", n.RoleNames)
+		f.field("AccessStorageType", n.AccessStorageType)
+		eachItem(f, "Settings", n.Settings)
+	case *AlterRole:
+		eachItem(f, "RoleRenamePairs", n.RoleRenamePairs)
+		eachItem(f, "Settings", n.Settings)
+	case *RoleName:
+		f.field("Name", n.Name)
+		f.field("Scope", n.Scope)
+		f.field("OnCluster", n.OnCluster)
+	case *RoleRenamePair:
+		f.field("RoleName", n.RoleName)
+		f.field("NewName", n.NewName)
+	case *RoleSetting:
+		eachItem(f, "SettingPairs", n.SettingPairs)
+		f.field("Modifier", n.Modifier)
+	case *SettingPair:
+		f.field("Name", n.Name)
+		f.field("Value", n.Value)
+	case *DropDatabase:
+		f.field("Name", n.Name)
+		f.field("OnCluster", n.OnCluster)
+	case *DropStmt:
+		f.field("Name", n.Name)
+		f.field("OnCluster", n.OnCluster)
+	case *DropUserOrRole:
+		eachItem(f, "Names", n.Names)
+		f.field("From", n.From)
+	case *TruncateTable:
+		f.field("Name", n.Name)
+		f.field("OnCluster", n.OnCluster)
+	case *RenameStmt:
+		eachItem(f, "TargetPairList", n.TargetPairList)
+		f.field("OnCluster", n.OnCluster)
+	case *TargetPair:
+		f.field("Old", n.Old)
+		f.field("New", n.New)
+
+	// ALTER TABLE
+	case *AlterTable:
+		f.field("TableIdentifier", n.TableIdentifier)
+		f.field("OnCluster", n.OnCluster)
+		eachItem(f, "AlterExprs", n.AlterExprs)
+	case *AlterTableAttachPartition:
+		f.field("Partition", n.Partition)
+		f.field("From", n.From)
+	case *AlterTableDetachPartition:
+		f.field("Partition", n.Partition)
+		f.field("Settings", n.Settings)
+	case *AlterTableDropPartition:
+		f.field("Partition", n.Partition)
+	case *AlterTableFreezePartition:
+		f.field("Partition", n.Partition)
+	case *AlterTableAddColumn:
+		f.field("Column", n.Column)
+		f.field("After", n.After)
+	case *AlterTableAddIndex:
+		f.field("Index", n.Index)
+		f.field("After", n.After)
+	case *AlterTableDropColumn:
+		f.field("ColumnName", n.ColumnName)
+	case *AlterTableDropIndex:
+		f.field("IndexName", n.IndexName)
+	case *AlterTableClearColumn:
+		f.field("ColumnName", n.ColumnName)
+		f.field("PartitionExpr", n.PartitionExpr)
+	case *AlterTableClearIndex:
+		f.field("IndexName", n.IndexName)
+		f.field("PartitionExpr", n.PartitionExpr)
+	case *AlterTableRenameColumn:
+		f.field("OldColumnName", n.OldColumnName)
+		f.field("NewColumnName", n.NewColumnName)
+	case *AlterTableModifyTTL:
+		f.field("TTL", n.TTL)
+	case *AlterTableModifyColumn:
+		f.field("Column", n.Column)
+		f.field("RemovePropertyType", n.RemovePropertyType)
+	case *AlterTableReplacePartition:
+		f.field("Partition", n.Partition)
+		f.field("Table", n.Table)
+
+	// Other statements
+	case *UseExpr:
+		f.field("Database", n.Database)
+	case *SetExpr:
+		f.field("Settings", n.Settings)
+	case *FormatExpr:
+		f.field("Format", n.Format)
+	case *OptimizeExpr:
+		f.field("Table", n.Table)
+		f.field("OnCluster", n.OnCluster)
+		f.field("Partition", n.Partition)
+		f.field("Deduplicate", n.Deduplicate)
+	case *DeduplicateExpr:
+		f.field("By", n.By)
+		f.field("Except", n.Except)
+	case *SystemExpr:
+		f.field("Expr", n.Expr)
+	case *SystemFlushExpr:
+		f.field("Distributed", n.Distributed)
+	case *SystemReloadExpr:
+		f.field("Dictionary", n.Dictionary)
+	case *SystemSyncExpr:
+		f.field("Cluster", n.Cluster)
+	case *SystemCtrlExpr:
+		f.field("Cluster", n.Cluster)
+	case *DeleteFromExpr:
+		f.field("Table", n.Table)
+		f.field("OnCluster", n.OnCluster)
+		f.field("WhereExpr", n.WhereExpr)
+	case *InsertExpr:
+		f.field("Table", n.Table)
+		f.field("ColumnNames", n.ColumnNames)
+		f.field("Format", n.Format)
+		eachItem(f, "Values", n.Values)
+		f.field("SelectExpr", n.SelectExpr)
+	case *ColumnNamesExpr:
+		for i := range n.ColumnNames {
+			f("ColumnNames", i, &n.ColumnNames[i])
+		}
+	case *ValuesExpr:
+		eachItem(f, "Values", n.Values)
+	case *CheckExpr:
+		f.field("Table", n.Table)
+		f.field("Partition", n.Partition)
+	case *ExplainExpr:
+		f.field("Statement", n.Statement)
+	case *GrantPrivilegeExpr:
+		f.field("OnCluster", n.OnCluster)
+		eachItem(f, "Privileges", n.Privileges)
+		f.field("On", n.On)
+		eachItem(f, "To", n.To)
+	case *PrivilegeExpr:
+		f.field("Params", n.Params)
+
+	default:
+		panic(fmt.Sprintf("parser.Walk: unexpected node type %T", n))
+	}
+}
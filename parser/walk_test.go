@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// collectNodes gathers every non-nil node reachable from expr via reflection,
+// used as the reference for what Walk is expected to visit.
+func collectNodes(v reflect.Value, nodes map[Expr]int) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			collectNodes(v.Elem(), nodes)
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if v.Type().Implements(exprType) {
+			nodes[v.Interface().(Expr)]++
+		}
+		collectNodes(v.Elem(), nodes)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if field.Kind() == reflect.Struct && field.Addr().Type().Implements(exprType) {
+				collectNodes(field.Addr(), nodes)
+				continue
+			}
+			collectNodes(field, nodes)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if elem.Kind() == reflect.Struct {
+				elem = elem.Addr()
+			}
+			collectNodes(elem, nodes)
+		}
+	}
+}
+
+func parseTestdataStatements(t *testing.T) map[string][]Expr {
+	t.Helper()
+	result := make(map[string][]Expr)
+	for _, dir := range []string{"./testdata/dml", "./testdata/ddl", "./testdata/query", "./testdata/basic"} {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".sql") {
+				continue
+			}
+			fileBytes, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			require.NoError(t, err)
+			stmts, err := NewParser(string(fileBytes)).ParseStatements()
+			require.NoError(t, err)
+			result[entry.Name()] = stmts
+		}
+	}
+	return result
+}
+
+func TestWalk_VisitsAllNodes(t *testing.T) {
+	for name, stmts := range parseTestdataStatements(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, stmt := range stmts {
+				expected := make(map[Expr]int)
+				collectNodes(reflect.ValueOf(stmt), expected)
+
+				visited := make(map[Expr]int)
+				Inspect(stmt, func(node Expr) bool {
+					if node != nil {
+						visited[node]++
+					}
+					return true
+				})
+				for node, count := range expected {
+					require.Equalf(t, count, visited[node], "node %T: %s", node, node.String(0))
+				}
+				require.Equal(t, len(expected), len(visited))
+			}
+		})
+	}
+}
+
+func TestInspect(t *testing.T) {
+	sql := `SELECT a.id, count(b.id) FROM db.t1 AS a
+		JOIN t2 AS b ON a.id = b.id
+		WHERE a.id IN (SELECT id FROM db.t3) GROUP BY a.id`
+	stmts, err := NewParser(sql).ParseStatements()
+	require.NoError(t, err)
+	require.Len(t, stmts, 1)
+
+	t.Run("collect nodes in source order", func(t *testing.T) {
+		var tables []string
+		var functions []string
+		Inspect(stmts[0], func(node Expr) bool {
+			switch n := node.(type) {
+			case *TableIdentifier:
+				tables = append(tables, n.String(0))
+			case *FunctionExpr:
+				functions = append(functions, n.Name.Name)
+			}
+			return true
+		})
+		require.Equal(t, []string{"db.t1", "t2", "db.t3"}, tables)
+		require.Equal(t, []string{"count"}, functions)
+	})
+
+	t.Run("prune subtrees", func(t *testing.T) {
+		var tables []string
+		Inspect(stmts[0], func(node Expr) bool {
+			switch n := node.(type) {
+			case *WhereExpr:
+				return false
+			case *TableIdentifier:
+				tables = append(tables, n.String(0))
+			}
+			return true
+		})
+		require.Equal(t, []string{"db.t1", "t2"}, tables)
+	})
+
+	t.Run("visit nil after children", func(t *testing.T) {
+		depth, maxDepth := 0, 0
+		Inspect(stmts[0], func(node Expr) bool {
+			if node == nil {
+				depth--
+				return false
+			}
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+			return true
+		})
+		require.Equal(t, 0, depth)
+		require.Greater(t, maxDepth, 1)
+	})
+}